@@ -0,0 +1,12 @@
+data "sotoon_iam_access_check" "ci_delete_prod" {
+  service_user_id = "33333333-3333-3333-3333-333333333333"
+  action          = "DELETE"
+  object          = "bucket/production/*"
+}
+
+check "ci_cannot_delete_production" {
+  assert {
+    condition     = !data.sotoon_iam_access_check.ci_delete_prod.allowed
+    error_message = "The CI service user can delete production buckets through ${data.sotoon_iam_access_check.ci_delete_prod.source}."
+  }
+}
@@ -0,0 +1,15 @@
+data "sotoon_iam_effective_permissions" "alice" {
+  user_id = "22222222-2222-2222-2222-222222222222"
+}
+
+# Every action alice is allowed, with the group and role it comes from
+output "alice_allowed" {
+  value = [
+    for p in data.sotoon_iam_effective_permissions.alice.permissions :
+    "${p.action} ${p.object} via ${p.source}" if !p.deny
+  ]
+}
+
+data "sotoon_iam_effective_permissions" "ci" {
+  service_user_id = "33333333-3333-3333-3333-333333333333"
+}
@@ -0,0 +1,12 @@
+data "sotoon_iam_graph" "workspace" {}
+
+# Commit both files so privilege changes show up in review
+resource "local_file" "iam_graph_json" {
+  filename = "${path.module}/iam-graph.json"
+  content  = data.sotoon_iam_graph.workspace.json
+}
+
+resource "local_file" "iam_graph_dot" {
+  filename = "${path.module}/iam-graph.dot"
+  content  = data.sotoon_iam_graph.workspace.dot
+}
@@ -0,0 +1,7 @@
+data "sotoon_iam_group" "developers" {
+  name = "developers"
+}
+
+output "developers_group_id" {
+  value = data.sotoon_iam_group.developers.id
+}
@@ -5,4 +5,9 @@ data "sotoon_iam_groups" "all" {
 output "all_group_names" {
   description = "A list of all group names in the workspace."
   value       = data.sotoon_iam_groups.all.groups.*.name
-}
\ No newline at end of file
+}
+
+data "sotoon_iam_groups" "team" {
+  workspace_id = "11111111-1111-1111-1111-111111111111"
+  name_prefix  = "team-"
+}
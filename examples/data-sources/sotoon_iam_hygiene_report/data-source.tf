@@ -0,0 +1,14 @@
+data "sotoon_iam_hygiene_report" "workspace" {
+  expires_within = "14d"
+}
+
+check "iam_hygiene" {
+  assert {
+    condition     = data.sotoon_iam_hygiene_report.workspace.issue_count == 0
+    error_message = "IAM hygiene problems found: ${jsonencode({
+      orphan_service_users = data.sotoon_iam_hygiene_report.workspace.orphan_service_users[*].name
+      unused_roles         = data.sotoon_iam_hygiene_report.workspace.unused_roles[*].name
+      stale_tokens         = data.sotoon_iam_hygiene_report.workspace.stale_tokens[*].name
+    })}"
+  }
+}
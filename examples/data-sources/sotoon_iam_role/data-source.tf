@@ -0,0 +1,8 @@
+data "sotoon_iam_role" "viewer" {
+  name  = "viewer"
+  scope = "global"
+}
+
+output "viewer_role_id" {
+  value = data.sotoon_iam_role.viewer.id
+}
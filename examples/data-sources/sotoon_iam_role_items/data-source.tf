@@ -0,0 +1,8 @@
+data "sotoon_iam_role_items" "bucket_reader" {
+  role_id = "66666666-6666-6666-6666-666666666666"
+}
+
+output "bucket_reader_item_keys" {
+  description = "Item keys accepted when binding the role."
+  value       = data.sotoon_iam_role_items.bucket_reader.items.*.key
+}
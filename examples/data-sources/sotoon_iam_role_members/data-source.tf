@@ -0,0 +1,20 @@
+data "sotoon_iam_role" "admin" {
+  name = "admin"
+}
+
+data "sotoon_iam_role_members" "admin" {
+  role_id       = data.sotoon_iam_role.admin.id
+  expand_groups = true
+}
+
+output "admin_user_emails" {
+  value = data.sotoon_iam_role_members.admin.users.*.email
+}
+
+output "admin_groups" {
+  value = data.sotoon_iam_role_members.admin.groups.*.name
+}
+
+output "everyone_holding_admin" {
+  value = data.sotoon_iam_role_members.admin.all_user_ids
+}
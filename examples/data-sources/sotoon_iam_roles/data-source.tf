@@ -10,4 +10,12 @@ output "all_role_names" {
 output "global_role_names" {
   description = "All role names bound to the user."
   value       = data.sotoon_iam_roles.all.global_roles.*.name
-}
\ No newline at end of file
+}
+
+# Global roles of one service whose name ends with "-viewer"
+data "sotoon_iam_roles" "viewers" {
+  workspace_id = "11111111-1111-1111-1111-111111111111"
+  service      = "object-storage"
+  name_regex   = "-viewer$"
+  global_only  = true
+}
@@ -0,0 +1,7 @@
+data "sotoon_iam_rule" "s3_get_object" {
+  name = "s3-get-object"
+}
+
+output "s3_get_object_actions" {
+  value = data.sotoon_iam_rule.s3_get_object.actions
+}
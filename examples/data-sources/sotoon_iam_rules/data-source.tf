@@ -30,3 +30,10 @@ output "all_global_rules" {
     }
   ]
 }
+
+# Rules allowing GET on bucket objects
+data "sotoon_iam_rules" "bucket_readers" {
+  workspace_id    = "11111111-1111-1111-1111-111111111111"
+  action_contains = "GET"
+  object_prefix   = "bucket/"
+}
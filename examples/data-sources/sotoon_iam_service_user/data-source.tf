@@ -0,0 +1,7 @@
+data "sotoon_iam_service_user" "ci" {
+  name = "ci-builder"
+}
+
+output "ci_service_user_id" {
+  value = data.sotoon_iam_service_user.ci.id
+}
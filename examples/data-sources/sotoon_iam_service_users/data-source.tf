@@ -6,3 +6,7 @@ data "sotoon_iam_service_users" "all" {
 output "all_service_users" {
   value = data.sotoon_iam_service_users.all.users
 }
+
+data "sotoon_iam_service_users" "ci" {
+  name_prefix = "ci-"
+}
@@ -0,0 +1,15 @@
+# Tokens expiring in the next 30 days, or already expired
+data "sotoon_iam_tokens" "expiring" {
+  expires_within = "720h"
+}
+
+# Tokens that never expire and should be rotated to expiring ones
+data "sotoon_iam_tokens" "permanent" {
+  never_expires = true
+}
+
+output "tokens_to_rotate" {
+  value = [
+    for t in data.sotoon_iam_tokens.expiring.tokens : "${t.owner_kind}/${coalesce(t.owner_name, t.owner_id)}: ${t.name} (${t.expires_at})"
+  ]
+}
@@ -5,4 +5,11 @@ data "sotoon_iam_users" "all" {
 output "all_user_emails" {
   description = "A list of all user emails in the workspace."
   value       = data.sotoon_iam_users.all.users.*.email
-}
\ No newline at end of file
+}
+
+# Active users of one email domain
+data "sotoon_iam_users" "company" {
+  workspace_id = "11111111-1111-1111-1111-111111111111"
+  email_domain = "example.com"
+  is_suspended = false
+}
@@ -0,0 +1,2 @@
+# Group members can be imported using <group_id>/<user_id>
+terraform import 'sotoon_iam_group_member.dev["alice"]' 33333333-3333-3333-3333-333333333333/44444444-4444-4444-4444-444444444444
@@ -0,0 +1,14 @@
+variable "dev_group_members" {
+  type = map(string)
+  default = {
+    alice = "44444444-4444-4444-4444-444444444444"
+    bob   = "44444444-4444-4444-4444-555555555555"
+  }
+}
+
+resource "sotoon_iam_group_member" "dev" {
+  for_each = var.dev_group_members
+
+  group_id = "33333333-3333-3333-3333-333333333333"
+  user_id  = each.value
+}
@@ -9,3 +9,8 @@ resource "sotoon_iam_group_role" "bind" {
 output "group_role_bind" {
   value = sotoon_iam_group_role.bind.id
 }
+
+resource "sotoon_iam_group_role" "bind_by_name" {
+  group_id   = "33333333-3333-3333-3333-333333333333"
+  role_names = ["viewer", "bucket-reader"]
+}
@@ -23,3 +23,23 @@ output "admin_role_id" {
   description = "The UUID of the admin role"
   value       = sotoon_iam_role.admin.id
 }
+
+resource "sotoon_iam_role" "auditor" {
+  name            = "auditor"
+  description     = "a role whose rules are fully managed by terraform"
+  exclusive_rules = true
+  rules = [
+    "77777777-7777-7777-7777-999999999999",
+  ]
+}
+
+# Rules can be attached by name; scope picks between workspace and global rules.
+resource "sotoon_iam_role" "bucket_reader" {
+  name        = "bucket-reader"
+  description = "read-only access to object storage"
+  scope       = "global"
+  rule_names = [
+    "s3-list-buckets",
+    "s3-get-object",
+  ]
+}
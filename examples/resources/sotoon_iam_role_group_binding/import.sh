@@ -0,0 +1,2 @@
+# Role group bindings can be imported using <role_id>/<group_id>
+terraform import sotoon_iam_role_group_binding.developers 66666666-6666-6666-6666-666666666666/33333333-3333-3333-3333-333333333333
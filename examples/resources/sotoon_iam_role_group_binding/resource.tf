@@ -0,0 +1,4 @@
+resource "sotoon_iam_role_group_binding" "developers" {
+  role_id  = "66666666-6666-6666-6666-666666666666"
+  group_id = "33333333-3333-3333-3333-333333333333"
+}
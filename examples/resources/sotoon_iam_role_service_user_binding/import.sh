@@ -0,0 +1,2 @@
+# Role service user bindings can be imported using <role_id>/<service_user_id>
+terraform import sotoon_iam_role_service_user_binding.ci 66666666-6666-6666-6666-666666666666/77777777-7777-7777-7777-777777777777
@@ -0,0 +1,4 @@
+resource "sotoon_iam_role_service_user_binding" "ci" {
+  role_id         = "66666666-6666-6666-6666-666666666666"
+  service_user_id = "77777777-7777-7777-7777-777777777777"
+}
@@ -0,0 +1,2 @@
+# Role user bindings can be imported using <role_id>/<user_id>
+terraform import sotoon_iam_role_user_binding.viewer 66666666-6666-6666-6666-666666666666/44444444-4444-4444-4444-444444444444
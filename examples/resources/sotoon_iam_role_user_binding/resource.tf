@@ -0,0 +1,7 @@
+resource "sotoon_iam_role_user_binding" "viewer" {
+  role_id = "66666666-6666-6666-6666-666666666666"
+  user_id = "44444444-4444-4444-4444-444444444444"
+  items = {
+    "project" = "my-project"
+  }
+}
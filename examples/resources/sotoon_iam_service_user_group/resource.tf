@@ -10,3 +10,10 @@ output "group_user_bind" {
 }
 
 
+
+resource "sotoon_iam_service_user_group" "bind_builder_by_group_name" {
+  group_name = "developers"
+  service_user_ids = [
+    "44444444-4444-4444-4444-444444444444",
+  ]
+}
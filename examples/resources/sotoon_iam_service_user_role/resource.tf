@@ -12,3 +12,11 @@ resource "sotoon_iam_service_user_role" "bind_service_user_to_role" {
 output "service_user_role_binding" {
   value = sotoon_iam_service_user_role.bind_service_user_to_role.id
 }
+
+resource "sotoon_iam_service_user_role" "bind_by_role_name" {
+  role_name = "viewer"
+  scope     = "workspace"
+  service_user_ids = [
+    "44444444-4444-4444-4444-444444444444",
+  ]
+}
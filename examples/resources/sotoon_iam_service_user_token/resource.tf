@@ -7,3 +7,32 @@ output "service_user_token_value" {
   value       = sotoon_iam_service_user_token.builder_token.value
   sensitive   = true
 }
+
+# Rotated every 30 days; the previous token stays valid for one more day
+resource "sotoon_iam_service_user_token" "deployer_token" {
+  service_user_id = "44444444-4444-4444-4444-444444444444"
+  name            = "deployer"
+
+  rotation {
+    rotate_after = "720h"
+    overlap      = "24h"
+  }
+}
+
+output "deployer_token_current" {
+  value     = sotoon_iam_service_user_token.deployer_token.current_value
+  sensitive = true
+}
+
+output "deployer_token_previous" {
+  value     = sotoon_iam_service_user_token.deployer_token.previous_value
+  sensitive = true
+}
+
+# Valid for 90 days and replaced once it is within a week of its expiry
+resource "sotoon_iam_service_user_token" "monitoring_token" {
+  service_user_id = "44444444-4444-4444-4444-444444444444"
+  name            = "monitoring"
+  expires_in      = "90d"
+  renew_before    = "7d"
+}
@@ -6,3 +6,30 @@ output "user_id" {
   description = "The unique ID of the created user."
   value       = sotoon_iam_user.user.id
 }
+
+# Offboarding through Terraform: destroying this resource removes the user
+# from the workspace after detaching all of their group and role bindings.
+resource "sotoon_iam_user" "contractor" {
+  email                 = "contractor@example.com"
+  remove_on_destroy     = true
+  force_detach_bindings = true
+}
+
+# Fails the apply unless the invitation is accepted within 30 minutes.
+# An invitation that expires unaccepted is re-sent on the next apply.
+resource "sotoon_iam_user" "new_hire" {
+  email               = "new.hire@example.com"
+  invitation_validity = "72h"
+  wait_for_acceptance = "30m"
+}
+
+output "new_hire_status" {
+  value = sotoon_iam_user.new_hire.status
+}
+
+# Temporarily blocks access, e.g. during a leave of absence or an incident.
+# Set back to false to reactivate the user.
+resource "sotoon_iam_user" "on_leave" {
+  email     = "on.leave@example.com"
+  suspended = true
+}
@@ -4,3 +4,22 @@ resource "sotoon_iam_user_group_membership" "dev_group_members" {
     "44444444-4444-4444-4444-444444444444",
   ]
 }
+
+# With exclusive = true the group holds exactly these users; members added
+# outside of Terraform are removed on the next apply.
+resource "sotoon_iam_user_group_membership" "ops_group_members" {
+  group_id  = "55555555-5555-5555-5555-555555555555"
+  exclusive = true
+  user_ids = [
+    "44444444-4444-4444-4444-444444444444",
+  ]
+}
+
+# Members can be referenced by email instead of UUID.
+resource "sotoon_iam_user_group_membership" "qa_group_members" {
+  group_id = "33333333-3333-3333-3333-333333333333"
+  user_emails = [
+    "alice@example.com",
+    "bob@example.com",
+  ]
+}
@@ -0,0 +1,19 @@
+resource "sotoon_iam_user_invitation_batch" "data_team" {
+  emails = [
+    "alice@example.com",
+    "bob@example.com",
+    "carol@example.com",
+  ]
+  invitation_validity = "72h"
+}
+
+output "pending_invitations" {
+  value = sotoon_iam_user_invitation_batch.data_team.pending_emails
+}
+
+output "failed_invitations" {
+  value = [
+    for inv in sotoon_iam_user_invitation_batch.data_team.invitations : inv
+    if inv.status == "failed"
+  ]
+}
@@ -13,3 +13,28 @@ resource "sotoon_iam_user_role" "bind_user_to_role" {
 output "user_role_bind_id" {
   value = sotoon_iam_user_role.bind_user_to_role.id
 }
+
+# Scope the same role differently per user with binding blocks.
+resource "sotoon_iam_user_role" "namespace_admins" {
+  role_id = "66666666-6666-6666-6666-666666666666"
+
+  binding {
+    id    = "44444444-4444-4444-4444-444444444444"
+    items = { namespace = "dev" }
+  }
+
+  binding {
+    id    = "44444444-4444-4444-4444-555555555555"
+    items = { namespace = "prod" }
+  }
+}
+
+# Bind users by email; each address must already be a workspace member.
+resource "sotoon_iam_user_role" "viewers" {
+  role_id = "77777777-7777-7777-7777-777777777777"
+
+  user_emails = [
+    "alice@example.com",
+    "bob@example.com",
+  ]
+}
@@ -0,0 +1,4 @@
+# User tokens of the provider user can be imported using the token UUID. The token value cannot be recovered.
+# An imported token keeps the expiry read from the API: expires_in in the configuration does not replace it,
+# and with renew_before it is replaced once it is that close to that expiry.
+terraform import sotoon_iam_user_token.me 55555555-5555-5555-5555-555555555555
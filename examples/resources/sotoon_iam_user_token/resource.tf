@@ -1,6 +1,6 @@
 resource "sotoon_iam_user_token" "me" {
-  name      = "developer-token"
-  expire_at = "2025-10-30T00:00:00Z"
+  name       = "developer-token"
+  expires_at = "2025-10-30T00:00:00Z"
 }
 
 output "new_user_token" {
@@ -8,3 +8,10 @@ output "new_user_token" {
   value       = sotoon_iam_user_token.me.value
   sensitive   = true
 }
+
+# Valid for 90 days and replaced once it is within a week of its expiry
+resource "sotoon_iam_user_token" "ci" {
+  name         = "ci-token"
+  expires_in   = "90d"
+  renew_before = "7d"
+}
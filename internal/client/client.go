@@ -117,8 +117,13 @@ func NewClient(host, token, workspace, userID string, shouldLog bool) (*Client,
 // --- IAM User Functions ---
 
 func (c *Client) InviteUser(ctx context.Context, email string) (*iam.IamUserInvitation, error) {
+	return c.InviteUsers(ctx, []string{email})
+}
+
+// InviteUsers sends one invitation request for all emails. A rejected request fails as a whole.
+func (c *Client) InviteUsers(ctx context.Context, emails []string) (*iam.IamUserInvitation, error) {
 
-	res, err := c.sotoonSdk.Iam_v1.InviteUsersToWorkspaceWithResponse(ctx, c.Workspace, iam.IamInviteRequest{Emails: []string{email}})
+	res, err := c.sotoonSdk.Iam_v1.InviteUsersToWorkspaceWithResponse(ctx, c.Workspace, iam.IamInviteRequest{Emails: emails})
 
 	if err != nil {
 		return nil, err
@@ -127,6 +132,9 @@ func (c *Client) InviteUser(ctx context.Context, email string) (*iam.IamUserInvi
 	if res.StatusCode() == 200 {
 		return res.JSON200, nil
 	}
+	if res.JSON400 != nil {
+		return nil, fmt.Errorf("invitation rejected: %s", res.JSON400.Reason)
+	}
 	tflog.Warn(ctx, "this should not happen", map[string]interface{}{"statusCode": res.StatusCode()})
 	return nil, ErrNotFound
 }
@@ -155,6 +163,19 @@ func (c *Client) GetWorkspaceUsers(ctx context.Context, workspaceID *uuid.UUID)
 	return nil, ErrNotFound
 }
 
+// GetWorkspaceUsersByEmail lists the workspace users with the given email, filtered by the API
+func (c *Client) GetWorkspaceUsersByEmail(ctx context.Context, workspaceID *uuid.UUID, email string) ([]iam.IamUser, error) {
+	res, err := c.sotoonSdk.Iam_v1.ListWorkspaceUsersWithResponse(ctx, workspaceID.String(), &iam.ListWorkspaceUsersParams{Email: &email})
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode() == 200 {
+		return *res.JSON200, nil
+	}
+	tflog.Warn(ctx, "this should not happen", map[string]interface{}{"statusCode": res.StatusCode()})
+	return nil, ErrNotFound
+}
+
 func (c *Client) GetWorkspaceUserByUUID(ctx context.Context, workspaceID *uuid.UUID, userID string) (*iam.IamUserWorkspaceDetailedUser, error) {
 	res, err := c.sotoonSdk.Iam_v1.GetDetailedWorkspaceUserWithResponse(ctx, workspaceID.String(), userID)
 	if err != nil {
@@ -168,18 +189,14 @@ func (c *Client) GetWorkspaceUserByUUID(ctx context.Context, workspaceID *uuid.U
 }
 
 func (c *Client) GetWorkspaceUserByEmail(ctx context.Context, workspaceID *uuid.UUID, email string) (*iam.IamUser, error) {
-	res, err := c.sotoonSdk.Iam_v1.ListWorkspaceUsersWithResponse(ctx, workspaceID.String(), &iam.ListWorkspaceUsersParams{Email: &email})
+	users, err := c.GetWorkspaceUsersByEmail(ctx, workspaceID, email)
 	if err != nil {
 		return nil, err
 	}
-	if res.StatusCode() == 200 {
-		if len(*res.JSON200) == 0 {
-			return nil, ErrNotFound
-		}
-		return &(*res.JSON200)[0], nil
+	if len(users) == 0 {
+		return nil, ErrNotFound
 	}
-	tflog.Warn(ctx, "this should not happen", map[string]interface{}{"statusCode": res.StatusCode()})
-	return nil, ErrNotFound
+	return &users[0], nil
 }
 
 func (c *Client) GetWorkspaceGroups(ctx context.Context, workspaceID *uuid.UUID) ([]iam.IamGroup, error) {
@@ -286,20 +303,21 @@ func (c *Client) CreateMyUserToken(ctx context.Context, name string, expiresAt *
 	return nil, ErrNotFound
 }
 
+// GetMyUserToken finds a token of the provider user, ErrNotFound means the list has no token with that UUID
 func (c *Client) GetMyUserToken(ctx context.Context, tokenUUID *uuid.UUID) (*iam.IamUserToken, error) {
 	res, err := c.sotoonSdk.Iam_v1.ListUserTokensWithResponse(ctx, c.UserID)
 	if err != nil {
 		return nil, err
 	}
 
-	if res.StatusCode() == 200 {
-		for _, token := range *res.JSON200 {
-			if token.Uuid == tokenUUID.String() {
-				return &token, nil
-			}
+	if res.StatusCode() != 200 || res.JSON200 == nil {
+		return nil, fmt.Errorf("failed to list user tokens: unexpected status code %d", res.StatusCode())
+	}
+	for _, token := range *res.JSON200 {
+		if token.Uuid == tokenUUID.String() {
+			return &token, nil
 		}
 	}
-	tflog.Warn(ctx, "this should not happen", map[string]interface{}{"statusCode": res.StatusCode()})
 	return nil, ErrNotFound
 }
 
@@ -339,6 +357,48 @@ func (c *Client) GetUser(ctx context.Context, userUUID *uuid.UUID) (*iam.IamUser
 	return nil, ErrNotFound
 }
 
+// SuspendUser blocks a workspace member from accessing the workspace until AllowUser is called.
+func (c *Client) SuspendUser(ctx context.Context, userUUID *uuid.UUID) error {
+	res, err := c.sotoonSdk.Iam_v1.SuspendUserWithResponse(ctx, c.Workspace, userUUID.String(), iam.IamCreateUser{})
+	if err != nil {
+		return err
+	}
+	if res.StatusCode() == 200 {
+		return nil
+	}
+	tflog.Warn(ctx, "this should not happen", map[string]interface{}{"statusCode": res.StatusCode()})
+	return ErrNotFound
+}
+
+// AllowUser reactivates a suspended workspace member.
+func (c *Client) AllowUser(ctx context.Context, userUUID *uuid.UUID) error {
+	res, err := c.sotoonSdk.Iam_v1.AllowUserWithResponse(ctx, c.Workspace, userUUID.String(), iam.IamCreateUser{})
+	if err != nil {
+		return err
+	}
+	if res.StatusCode() == 200 {
+		return nil
+	}
+	tflog.Warn(ctx, "this should not happen", map[string]interface{}{"statusCode": res.StatusCode()})
+	return ErrNotFound
+}
+
+// RemoveUserFromWorkspace removes a member, or a user whose invitation is pending, from the workspace.
+func (c *Client) RemoveUserFromWorkspace(ctx context.Context, userUUID *uuid.UUID) error {
+	res, err := c.sotoonSdk.Iam_v1.RemoveUserFromWorkspaceWithResponse(ctx, c.Workspace, userUUID.String())
+	if err != nil {
+		return err
+	}
+	switch code := res.StatusCode(); {
+	case code >= 200 && code < 300:
+		return nil
+	case code == 404:
+		return ErrNotFound
+	default:
+		return fmt.Errorf("unexpected status code %d", code)
+	}
+}
+
 func (c *Client) DeleteMyUserToken(ctx context.Context, tokenUUID *uuid.UUID) error {
 	_, err := c.sotoonSdk.Iam_v1.DeleteUserTokenWithResponse(ctx, c.UserID, tokenUUID.String())
 	return err
@@ -648,10 +708,34 @@ func (c *Client) BulkAddServiceUsersToRole(ctx context.Context, roleUUID uuid.UU
 	return err
 }
 
+// BulkAddServiceUsersToRoleWithItems binds every service user with its own items, items[i] belongs to
+// serviceUserUUIDs[i]. A nil items slice binds without items.
+func (c *Client) BulkAddServiceUsersToRoleWithItems(ctx context.Context, roleUUID uuid.UUID, serviceUserUUIDs []string, items []map[string]string) error {
+	var itemsString *[]map[string]string
+	if items != nil {
+		itemsString = &items
+	}
+	_, err := c.sotoonSdk.Iam_v1.BulkAddServiceUsersToRoleWithResponse(ctx, c.Workspace, roleUUID.String(),
+		iam.IamBulkAddServiceUsersToRoleRequest{
+			ServiceUsers: serviceUserUUIDs,
+			Items:        itemsString,
+		})
+	return err
+}
+
 // --- IAM Role Functions ---
 
 func (c *Client) GetWorkspaceRoles(ctx context.Context, worksapceUUID string) ([]iam.IamRole, error) {
-	res, err := c.sotoonSdk.Iam_v1.ListRolesWithResponse(ctx, worksapceUUID, nil)
+	return c.GetWorkspaceRolesOfService(ctx, worksapceUUID, "")
+}
+
+// GetWorkspaceRolesOfService lists the roles of a workspace, filtered by the API to one service unless service is empty
+func (c *Client) GetWorkspaceRolesOfService(ctx context.Context, worksapceUUID string, service string) ([]iam.IamRole, error) {
+	var params *iam.ListRolesParams
+	if service != "" {
+		params = &iam.ListRolesParams{Service: &service}
+	}
+	res, err := c.sotoonSdk.Iam_v1.ListRolesWithResponse(ctx, worksapceUUID, params)
 	if err != nil {
 		return nil, err
 	}
@@ -782,6 +866,21 @@ func (c *Client) BulkAddUsersToRole(ctx context.Context, roleUUID uuid.UUID, uui
 	return err
 }
 
+// BulkAddUsersToRoleWithItems binds every user with its own items, items[i] belongs to uuids[i].
+// A nil items slice binds without items.
+func (c *Client) BulkAddUsersToRoleWithItems(ctx context.Context, roleUUID uuid.UUID, uuids []string, items []map[string]string) error {
+	var itemsString *[]map[string]string
+	if items != nil {
+		itemsString = &items
+	}
+	_, err := c.sotoonSdk.Iam_v1.BulkAddUsersToRoleWithResponse(ctx, c.Workspace, roleUUID.String(),
+		iam.IamBulkAddUsersToRoleRequest{
+			Users: uuids,
+			Items: itemsString,
+		})
+	return err
+}
+
 func (c *Client) UnbindRoleFromUser(ctx context.Context, roleUUID *uuid.UUID, userUUID *uuid.UUID) error {
 	_, err := c.sotoonSdk.Iam_v1.RemoveRoleFromUser(ctx, c.Workspace, roleUUID.String(), userUUID.String())
 	return err
@@ -789,6 +888,19 @@ func (c *Client) UnbindRoleFromUser(ctx context.Context, roleUUID *uuid.UUID, us
 
 // --- IAM Rule Functions ---
 
+// GetRuleRoles lists the roles of the workspace which hold the rule
+func (c *Client) GetRuleRoles(ctx context.Context, ruleUUID *uuid.UUID) ([]iam.IamRole, error) {
+	res, err := c.sotoonSdk.Iam_v1.ListRuleRolesWithResponse(ctx, c.Workspace, ruleUUID.String())
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode() == 200 {
+		return *res.JSON200, nil
+	}
+	tflog.Warn(ctx, "this should not happen", map[string]interface{}{"statusCode": res.StatusCode()})
+	return nil, ErrNotFound
+}
+
 func (c *Client) GetWorkspaceRules(ctx context.Context, workspace string) ([]iam.IamRule, error) {
 	res, err := c.sotoonSdk.Iam_v1.ListRulesWithResponse(ctx, workspace)
 	if err != nil {
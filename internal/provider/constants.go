@@ -1,8 +1,62 @@
 package provider
 
 import (
+	"time"
+
 	uuid "github.com/satori/go.uuid"
 )
 
 // GlobalWorkspaceUUID is the UUID for the global workspace that contains global roles and rules
 var GlobalWorkspaceUUID = uuid.FromStringOrNil("00000000-0000-0000-0000-000000000000")
+
+// Values of the status attribute of sotoon_iam_user
+const (
+	userStatusInvited   = "invited"
+	userStatusExpired   = "expired"
+	userStatusActive    = "active"
+	userStatusSuspended = "suspended"
+)
+
+// invitationStatusFailed marks an email of sotoon_iam_user_invitation_batch which the API rejected
+const invitationStatusFailed = "failed"
+
+// defaultInvitationValidity is how long an invitation is assumed to stay valid, the API does not return an expiry
+const defaultInvitationValidity = "168h"
+
+// invitationPollInterval is the delay between checks while waiting for an invitation to be accepted
+const invitationPollInterval = 10 * time.Second
+
+// Values of the scope argument used when resolving role and rule names
+const (
+	scopeWorkspace = "workspace"
+	scopeGlobal    = "global"
+)
+
+// Values of the decision attribute of sotoon_iam_access_check
+const (
+	accessAllow        = "allow"
+	accessDeny         = "deny"
+	accessImplicitDeny = "implicit_deny"
+)
+
+// Node kinds of sotoon_iam_graph
+const (
+	graphKindUser        = "user"
+	graphKindServiceUser = "service_user"
+	graphKindGroup       = "group"
+	graphKindRole        = "role"
+	graphKindRule        = "rule"
+)
+
+// Edge kinds of sotoon_iam_graph
+const (
+	graphEdgeMemberOf = "member_of"
+	graphEdgeHasRole  = "has_role"
+	graphEdgeHasRule  = "has_rule"
+)
+
+// Values of the status attribute of the stale tokens of sotoon_iam_hygiene_report
+const (
+	tokenStatusExpired  = "expired"
+	tokenStatusExpiring = "expiring"
+)
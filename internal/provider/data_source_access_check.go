@@ -0,0 +1,167 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"github.com/sotoon/terraform-provider-sotoon/internal/client"
+)
+
+func dataSourceAccessCheck() *schema.Resource {
+	return &schema.Resource{
+		Description: "Checks whether a user or service user may perform an action on an object, using the rules of its direct and group roles. " +
+			"A matching deny rule overrides every allow, and the principal is denied when no rule matches. " +
+			"A `*` in a rule object matches any sequence of characters and a `*` action matches every action. " +
+			"The check is evaluated by the provider, so it reflects the rules but not any other policy the API enforces.",
+		ReadContext: dataSourceAccessCheckRead,
+		Schema: map[string]*schema.Schema{
+			"user_id": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ExactlyOneOf: []string{"user_id", "service_user_id"},
+				Description:  "UUID of the user to check.",
+			},
+			"service_user_id": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ExactlyOneOf: []string{"user_id", "service_user_id"},
+				Description:  "UUID of the service user to check.",
+			},
+			"action": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The action to check, such as `DELETE`. Compared case-insensitively.",
+			},
+			"object": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The object to check, matched against the rule objects.",
+			},
+			"service": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only consider rules of this service.",
+			},
+			"allowed": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the action is allowed.",
+			},
+			"decision": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "`allow` when an allow rule matches, `deny` when a deny rule matches and `implicit_deny` when no rule matches.",
+			},
+			"rule_id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "UUID of the deciding rule. Empty for an implicit deny.",
+			},
+			"rule_name": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Name of the deciding rule. Empty for an implicit deny.",
+			},
+			"source": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Path from the principal to the deciding rule, as in `sotoon_iam_effective_permissions`. Empty for an implicit deny.",
+			},
+		},
+	}
+}
+
+func dataSourceAccessCheckRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client.Client)
+
+	permissions, principal, err := principalPermissions(ctx, c, d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	action := d.Get("action").(string)
+	object := d.Get("object").(string)
+	allowed, deciding := evaluateAccess(permissions, action, object, d.Get("service").(string))
+
+	decision, ruleID, ruleName, source := accessImplicitDeny, "", "", ""
+	if deciding != nil {
+		decision, ruleID, ruleName, source = accessDeny, deciding.RuleID, deciding.RuleName, deciding.source()
+		if allowed {
+			decision = accessAllow
+		}
+	}
+
+	if err := d.Set("allowed", allowed); err != nil {
+		return diag.FromErr(fmt.Errorf("failed to set allowed: %w", err))
+	}
+	if err := d.Set("decision", decision); err != nil {
+		return diag.FromErr(fmt.Errorf("failed to set decision: %w", err))
+	}
+	if err := d.Set("rule_id", ruleID); err != nil {
+		return diag.FromErr(fmt.Errorf("failed to set rule_id: %w", err))
+	}
+	if err := d.Set("rule_name", ruleName); err != nil {
+		return diag.FromErr(fmt.Errorf("failed to set rule_name: %w", err))
+	}
+	if err := d.Set("source", source); err != nil {
+		return diag.FromErr(fmt.Errorf("failed to set source: %w", err))
+	}
+
+	d.SetId("access-check:" + c.WorkspaceUUID.String() + ":" + principal + ":" + action + ":" + object)
+	return nil
+}
+
+// matchesWildcard reports whether value matches pattern, where every * in pattern matches any sequence of
+// characters, including /
+func matchesWildcard(pattern, value string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == value
+	}
+	if !strings.HasPrefix(value, parts[0]) {
+		return false
+	}
+	value = value[len(parts[0]):]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(value, part)
+		if i < 0 {
+			return false
+		}
+		value = value[i+len(part):]
+	}
+	return strings.HasSuffix(value, parts[len(parts)-1])
+}
+
+// reports whether p applies to the action and object, and to the service unless service is empty
+func (p permission) appliesTo(action, object, service string) bool {
+	if p.Action != "*" && !strings.EqualFold(p.Action, action) {
+		return false
+	}
+	if service != "" && p.Service != service {
+		return false
+	}
+	return matchesWildcard(p.Object, object)
+}
+
+// evaluateAccess decides whether the permissions allow the action on the object. A matching deny overrides
+// every allow. The deciding permission is nil when nothing matches, which denies implicitly.
+func evaluateAccess(permissions []permission, action, object, service string) (bool, *permission) {
+	var allow *permission
+	for i := range permissions {
+		p := permissions[i]
+		if !p.appliesTo(action, object, service) {
+			continue
+		}
+		if p.Deny {
+			return false, &p
+		}
+		if allow == nil {
+			allow = &p
+		}
+	}
+	return allow != nil, allow
+}
@@ -0,0 +1,302 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	uuid "github.com/satori/go.uuid"
+	iam "github.com/sotoon/sotoon-sdk-go/sdk/core/iam_v1"
+
+	"github.com/sotoon/terraform-provider-sotoon/internal/client"
+)
+
+func dataSourceEffectivePermissions() *schema.Resource {
+	return &schema.Resource{
+		Description: "Resolves what a user or service user can do in the workspace. " +
+			"Direct role bindings and roles inherited through every group membership are expanded into their rules, " +
+			"and every action is listed with the path that grants it.",
+		ReadContext: dataSourceEffectivePermissionsRead,
+		Schema: map[string]*schema.Schema{
+			"user_id": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ExactlyOneOf: []string{"user_id", "service_user_id"},
+				Description:  "UUID of the user to inspect.",
+			},
+			"service_user_id": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ExactlyOneOf: []string{"user_id", "service_user_id"},
+				Description:  "UUID of the service user to inspect.",
+			},
+			"permissions": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "One entry per action of every rule reachable from the principal, sorted by `source` and `action`. The same action appears once per path that grants it.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"action": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The action, such as `GET`.",
+						},
+						"object": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The object the rule applies to.",
+						},
+						"service": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The service the rule applies to.",
+						},
+						"deny": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "Whether the rule denies the action rather than allowing it.",
+						},
+						"rule_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "UUID of the rule.",
+						},
+						"rule_name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the rule.",
+						},
+						"role_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "UUID of the role holding the rule.",
+						},
+						"role_name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the role holding the rule.",
+						},
+						"group_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "UUID of the group the role is inherited from. Empty for direct bindings.",
+						},
+						"group_name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the group the role is inherited from. Empty for direct bindings.",
+						},
+						"source": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Path from the principal to the rule, such as `role:viewer/rule:bucket-read` or `group:devs/role:viewer/rule:bucket-read`.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceEffectivePermissionsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client.Client)
+
+	permissions, principal, err := principalPermissions(ctx, c, d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	out := make([]map[string]interface{}, 0, len(permissions))
+	for _, p := range permissions {
+		out = append(out, map[string]interface{}{
+			"action":     p.Action,
+			"object":     p.Object,
+			"service":    p.Service,
+			"deny":       p.Deny,
+			"rule_id":    p.RuleID,
+			"rule_name":  p.RuleName,
+			"role_id":    p.RoleID,
+			"role_name":  p.RoleName,
+			"group_id":   p.GroupID,
+			"group_name": p.GroupName,
+			"source":     p.source(),
+		})
+	}
+	if err := d.Set("permissions", out); err != nil {
+		return diag.FromErr(fmt.Errorf("failed to set permissions: %w", err))
+	}
+
+	d.SetId("effective-permissions:" + c.WorkspaceUUID.String() + ":" + principal)
+	return nil
+}
+
+// principalPermissions expands the rules reachable from the user_id or service_user_id of d. The principal is
+// returned in the form user:<uuid> or service-user:<uuid>.
+func principalPermissions(ctx context.Context, c *client.Client, d *schema.ResourceData) ([]permission, string, error) {
+	var grants []roleGrant
+	var principal string
+	var err error
+	if userID, ok := d.GetOk("user_id"); ok {
+		principal = "user:" + userID.(string)
+		grants, err = userRoleGrants(ctx, c, userID.(string))
+	} else {
+		serviceUserID := d.Get("service_user_id").(string)
+		principal = "service-user:" + serviceUserID
+		grants, err = serviceUserRoleGrants(ctx, c, serviceUserID)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	roleRules := map[string][]iam.IamRule{}
+	for _, g := range grants {
+		if _, ok := roleRules[g.RoleID]; ok {
+			continue
+		}
+		roleUUID, err := uuid.FromString(g.RoleID)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid role id %q returned by the API: %w", g.RoleID, err)
+		}
+		rules, err := c.GetRoleRules(ctx, &roleUUID)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to list rules of role %s: %w", g.RoleName, err)
+		}
+		roleRules[g.RoleID] = rules
+	}
+	return expandPermissions(grants, roleRules), principal, nil
+}
+
+// roles bound to the user directly and through its groups
+func userRoleGrants(ctx context.Context, c *client.Client, userID string) ([]roleGrant, error) {
+	userUUID, err := uuid.FromString(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user_id %q: %w", userID, err)
+	}
+	u, err := c.GetUserDetailed(ctx, &userUUID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user detail: %w", err)
+	}
+	if u.Uuid == nil {
+		return nil, fmt.Errorf("user %s not found", userID)
+	}
+
+	grants := []roleGrant{}
+	for _, r := range u.Roles {
+		grants = append(grants, roleGrant{RoleID: r.Uuid, RoleName: r.Name})
+	}
+	for _, g := range u.Groups {
+		inherited, err := groupRoleGrants(ctx, c, g.Uuid, g.Name)
+		if err != nil {
+			return nil, err
+		}
+		grants = append(grants, inherited...)
+	}
+	return grants, nil
+}
+
+// roles bound to the service user directly and through its groups
+func serviceUserRoleGrants(ctx context.Context, c *client.Client, serviceUserID string) ([]roleGrant, error) {
+	serviceUserUUID, err := uuid.FromString(serviceUserID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid service_user_id %q: %w", serviceUserID, err)
+	}
+	detail, err := c.GetWorkspaceServiceUserDetail(ctx, *c.WorkspaceUUID, serviceUserUUID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get service user detail: %w", err)
+	}
+	if detail == nil {
+		return nil, fmt.Errorf("service user %s not found", serviceUserID)
+	}
+
+	grants := []roleGrant{}
+	for _, r := range detail.Roles {
+		grants = append(grants, roleGrant{RoleID: r.Uuid, RoleName: r.Name})
+	}
+	for _, g := range detail.Groups {
+		inherited, err := groupRoleGrants(ctx, c, g.Uuid, g.Name)
+		if err != nil {
+			return nil, err
+		}
+		grants = append(grants, inherited...)
+	}
+	return grants, nil
+}
+
+func groupRoleGrants(ctx context.Context, c *client.Client, groupID, groupName string) ([]roleGrant, error) {
+	groupUUID, err := uuid.FromString(groupID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid group id %q returned by the API: %w", groupID, err)
+	}
+	roles, err := c.GetWorkspaceGroupRoleList(ctx, c.WorkspaceUUID, &groupUUID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list roles of group %s: %w", groupName, err)
+	}
+	grants := make([]roleGrant, 0, len(roles))
+	for _, r := range roles {
+		grants = append(grants, roleGrant{RoleID: r.Uuid, RoleName: r.Name, GroupID: groupID, GroupName: groupName})
+	}
+	return grants, nil
+}
+
+// roleGrant is a role bound to a principal, directly or through the group GroupID
+type roleGrant struct {
+	RoleID    string
+	RoleName  string
+	GroupID   string
+	GroupName string
+}
+
+// permission is a single action granted or denied by a rule, with the path that leads to it
+type permission struct {
+	Action    string
+	Object    string
+	Service   string
+	Deny      bool
+	RuleID    string
+	RuleName  string
+	RoleID    string
+	RoleName  string
+	GroupID   string
+	GroupName string
+}
+
+// source is the path from the principal to the rule, such as group:devs/role:viewer/rule:bucket-read
+func (p permission) source() string {
+	path := "role:" + p.RoleName + "/rule:" + p.RuleName
+	if p.GroupID != "" {
+		path = "group:" + p.GroupName + "/" + path
+	}
+	return path
+}
+
+// expands every grant into one permission per action of its role rules, sorted by source and action
+func expandPermissions(grants []roleGrant, roleRules map[string][]iam.IamRule) []permission {
+	out := []permission{}
+	for _, g := range grants {
+		for _, r := range roleRules[g.RoleID] {
+			for _, a := range r.Actions {
+				out = append(out, permission{
+					Action:    a,
+					Object:    r.Object,
+					Service:   r.ServiceObject,
+					Deny:      r.Deny,
+					RuleID:    r.Uuid,
+					RuleName:  r.Name,
+					RoleID:    g.RoleID,
+					RoleName:  g.RoleName,
+					GroupID:   g.GroupID,
+					GroupName: g.GroupName,
+				})
+			}
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].source() != out[j].source() {
+			return out[i].source() < out[j].source()
+		}
+		return out[i].Action < out[j].Action
+	})
+	return out
+}
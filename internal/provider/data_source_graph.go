@@ -0,0 +1,300 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	uuid "github.com/satori/go.uuid"
+	iam "github.com/sotoon/sotoon-sdk-go/sdk/core/iam_v1"
+
+	"github.com/sotoon/terraform-provider-sotoon/internal/client"
+)
+
+func dataSourceGraph() *schema.Resource {
+	return &schema.Resource{
+		Description: "Exports the IAM graph of the workspace: users and service users, the groups they belong to, " +
+			"the roles bound to principals and groups, and the rules of those roles. " +
+			"The graph is rendered as a JSON document and as Graphviz DOT, both sorted so an unchanged workspace renders identically.",
+		ReadContext: dataSourceGraphRead,
+		Schema: map[string]*schema.Schema{
+			"include_global_roles": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Include global roles bound to principals of the workspace. Every global role is checked for bindings, which costs two API calls per global role.",
+			},
+			"include_rules": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Include the rules of every role in the graph.",
+			},
+			"json": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The graph as a JSON document with `nodes` (`id`, `kind`, `name` and, for rules, `object`, `actions` and `deny`) and `edges` (`from`, `to`, `kind`).",
+			},
+			"dot": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The graph in the Graphviz DOT language. Deny rules are drawn in red.",
+			},
+		},
+	}
+}
+
+func dataSourceGraphRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client.Client)
+	g := newIAMGraph()
+
+	users, err := c.GetWorkspaceUsers(ctx, c.WorkspaceUUID)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("failed to list users: %w", err))
+	}
+	for _, u := range users {
+		g.addNode(graphNode{ID: u.Uuid, Kind: graphKindUser, Name: u.Email})
+	}
+
+	serviceUsers, err := c.GetServiceUsers(ctx)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("failed to list service users: %w", err))
+	}
+	for _, su := range serviceUsers {
+		g.addNode(graphNode{ID: su.Uuid, Kind: graphKindServiceUser, Name: su.Name})
+	}
+
+	// roles reached from a group or principal, the rules of these roles are added at the end
+	roles := map[string]string{}
+
+	groups, err := c.GetWorkspaceGroups(ctx, c.WorkspaceUUID)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("failed to list groups: %w", err))
+	}
+	for _, grp := range groups {
+		groupNode := g.addNode(graphNode{ID: grp.Uuid, Kind: graphKindGroup, Name: grp.Name})
+		groupUUID, err := uuid.FromString(grp.Uuid)
+		if err != nil {
+			return diag.Errorf("invalid group id %q returned by the API: %s", grp.Uuid, err)
+		}
+
+		members, err := c.GetWorkspaceGroupUsersList(ctx, c.WorkspaceUUID, &groupUUID)
+		if err != nil {
+			return diag.FromErr(fmt.Errorf("failed to list users of group %s: %w", grp.Name, err))
+		}
+		for _, u := range members {
+			userNode := g.addNode(graphNode{ID: u.Uuid, Kind: graphKindUser, Name: u.Email})
+			g.addEdge(userNode, groupNode, graphEdgeMemberOf)
+		}
+
+		serviceMembers, err := c.GetAllGroupServiceUserList(ctx, c.WorkspaceUUID, &groupUUID)
+		if err != nil {
+			return diag.FromErr(fmt.Errorf("failed to list service users of group %s: %w", grp.Name, err))
+		}
+		for _, su := range serviceMembers {
+			serviceUserNode := g.addNode(graphNode{ID: su.Uuid, Kind: graphKindServiceUser, Name: su.Name})
+			g.addEdge(serviceUserNode, groupNode, graphEdgeMemberOf)
+		}
+
+		groupRoles, err := c.GetWorkspaceGroupRoleList(ctx, c.WorkspaceUUID, &groupUUID)
+		if err != nil {
+			return diag.FromErr(fmt.Errorf("failed to list roles of group %s: %w", grp.Name, err))
+		}
+		for _, r := range groupRoles {
+			roles[r.Uuid] = r.Name
+			g.addEdge(groupNode, g.addNode(graphNode{ID: r.Uuid, Kind: graphKindRole, Name: r.Name}), graphEdgeHasRole)
+		}
+	}
+
+	workspaceRoles, err := c.GetWorkspaceRoles(ctx, c.Workspace)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("failed to list roles: %w", err))
+	}
+	if err := addRoleBindings(ctx, c, g, workspaceRoles, roles, true); err != nil {
+		return diag.FromErr(err)
+	}
+	if d.Get("include_global_roles").(bool) {
+		globalRoles, err := c.GetWorkspaceRoles(ctx, GlobalWorkspaceUUID.String())
+		if err != nil {
+			return diag.FromErr(fmt.Errorf("failed to list global roles: %w", err))
+		}
+		if err := addRoleBindings(ctx, c, g, globalRoles, roles, false); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	if d.Get("include_rules").(bool) {
+		for roleID, roleName := range roles {
+			roleUUID, err := uuid.FromString(roleID)
+			if err != nil {
+				return diag.Errorf("invalid role id %q returned by the API: %s", roleID, err)
+			}
+			rules, err := c.GetRoleRules(ctx, &roleUUID)
+			if err != nil {
+				return diag.FromErr(fmt.Errorf("failed to list rules of role %s: %w", roleName, err))
+			}
+			roleNode := g.addNode(graphNode{ID: roleID, Kind: graphKindRole, Name: roleName})
+			for _, r := range rules {
+				ruleNode := g.addNode(graphNode{ID: r.Uuid, Kind: graphKindRule, Name: r.Name, Object: r.Object, Actions: r.Actions, Deny: r.Deny})
+				g.addEdge(roleNode, ruleNode, graphEdgeHasRule)
+			}
+		}
+	}
+
+	doc, err := g.JSON()
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("failed to render the graph: %w", err))
+	}
+	if err := d.Set("json", doc); err != nil {
+		return diag.FromErr(fmt.Errorf("failed to set json: %w", err))
+	}
+	if err := d.Set("dot", g.DOT()); err != nil {
+		return diag.FromErr(fmt.Errorf("failed to set dot: %w", err))
+	}
+
+	d.SetId("iam-graph:" + c.WorkspaceUUID.String())
+	return nil
+}
+
+// addRoleBindings adds the users and service users bound to each role. Roles without bindings are only added
+// when keepUnbound is set, the others are recorded in roles.
+func addRoleBindings(ctx context.Context, c *client.Client, g *iamGraph, list []iam.IamRole, roles map[string]string, keepUnbound bool) error {
+	for _, r := range list {
+		roleUUID, err := uuid.FromString(r.Uuid)
+		if err != nil {
+			return fmt.Errorf("invalid role id %q returned by the API: %w", r.Uuid, err)
+		}
+		users, err := c.GetRoleUsers(ctx, &roleUUID)
+		if err != nil {
+			return fmt.Errorf("failed to list users of role %s: %w", r.Name, err)
+		}
+		serviceUsers, err := c.GetRoleServiceUsers(ctx, &roleUUID)
+		if err != nil {
+			return fmt.Errorf("failed to list service users of role %s: %w", r.Name, err)
+		}
+		if _, bound := roles[r.Uuid]; !bound && !keepUnbound && len(users) == 0 && len(serviceUsers) == 0 {
+			continue
+		}
+
+		roles[r.Uuid] = r.Name
+		roleNode := g.addNode(graphNode{ID: r.Uuid, Kind: graphKindRole, Name: r.Name})
+		for _, u := range users {
+			g.addEdge(g.addNode(graphNode{ID: u.Uuid, Kind: graphKindUser, Name: u.Email}), roleNode, graphEdgeHasRole)
+		}
+		for _, su := range serviceUsers {
+			g.addEdge(g.addNode(graphNode{ID: su.Uuid, Kind: graphKindServiceUser, Name: su.Name}), roleNode, graphEdgeHasRole)
+		}
+	}
+	return nil
+}
+
+// graphNode is a principal, group, role or rule of the IAM graph, its ID is <kind>:<uuid>
+type graphNode struct {
+	ID      string   `json:"id"`
+	Kind    string   `json:"kind"`
+	Name    string   `json:"name"`
+	Object  string   `json:"object,omitempty"`
+	Actions []string `json:"actions,omitempty"`
+	Deny    bool     `json:"deny,omitempty"`
+}
+
+// graphEdge links a principal to a group or role, a group to a role, or a role to a rule
+type graphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Kind string `json:"kind"`
+}
+
+// iamGraph collects nodes and edges in any order and renders them sorted, so equal graphs render equally
+type iamGraph struct {
+	nodes map[string]graphNode
+	edges map[graphEdge]struct{}
+}
+
+func newIAMGraph() *iamGraph {
+	return &iamGraph{nodes: map[string]graphNode{}, edges: map[graphEdge]struct{}{}}
+}
+
+// adds the node and returns its id, a node added twice keeps the last details
+func (g *iamGraph) addNode(n graphNode) string {
+	n.ID = n.Kind + ":" + n.ID
+	g.nodes[n.ID] = n
+	return n.ID
+}
+
+func (g *iamGraph) addEdge(from, to, kind string) {
+	g.edges[graphEdge{From: from, To: to, Kind: kind}] = struct{}{}
+}
+
+func (g *iamGraph) sorted() ([]graphNode, []graphEdge) {
+	nodes := make([]graphNode, 0, len(g.nodes))
+	for _, n := range g.nodes {
+		nodes = append(nodes, n)
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+
+	edges := make([]graphEdge, 0, len(g.edges))
+	for e := range g.edges {
+		edges = append(edges, e)
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].From != edges[j].From {
+			return edges[i].From < edges[j].From
+		}
+		if edges[i].To != edges[j].To {
+			return edges[i].To < edges[j].To
+		}
+		return edges[i].Kind < edges[j].Kind
+	})
+	return nodes, edges
+}
+
+// JSON renders the graph as {"nodes": [...], "edges": [...]}
+func (g *iamGraph) JSON() (string, error) {
+	nodes, edges := g.sorted()
+	out, err := json.MarshalIndent(map[string]interface{}{"nodes": nodes, "edges": edges}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// DOT renders the graph in the Graphviz DOT language, deny rules are drawn in red
+func (g *iamGraph) DOT() string {
+	nodes, edges := g.sorted()
+	var b strings.Builder
+	b.WriteString("digraph iam {\n  rankdir=LR;\n")
+	for _, n := range nodes {
+		label := n.Kind + "\n" + n.Name
+		attrs := ""
+		switch n.Kind {
+		case graphKindUser, graphKindServiceUser:
+			attrs = ", shape=ellipse"
+		case graphKindGroup:
+			attrs = ", shape=box3d"
+		case graphKindRole:
+			attrs = ", shape=box"
+		case graphKindRule:
+			label += "\n" + strings.Join(n.Actions, ",") + " " + n.Object
+			attrs = ", shape=note"
+			if n.Deny {
+				attrs += ", color=red"
+			}
+		}
+		fmt.Fprintf(&b, "  %s [label=%s%s];\n", dotQuote(n.ID), dotQuote(label), attrs)
+	}
+	for _, e := range edges {
+		fmt.Fprintf(&b, "  %s -> %s [label=%s];\n", dotQuote(e.From), dotQuote(e.To), dotQuote(e.Kind))
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// quotes a DOT id, line breaks become \n
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
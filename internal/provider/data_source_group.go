@@ -0,0 +1,89 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"github.com/sotoon/terraform-provider-sotoon/internal/client"
+)
+
+func dataSourceGroup() *schema.Resource {
+	return &schema.Resource{
+		Description: "Looks up a single IAM group of the workspace by name or UUID. Fails when no group or several groups match.",
+		ReadContext: dataSourceGroupRead,
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"id", "name"},
+				Description:  "The UUID of the group.",
+			},
+			"name": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"id", "name"},
+				Description:  "The name of the group.",
+			},
+			"description": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The description of the group.",
+			},
+			"created_at": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Timestamp when the group was created (RFC3339).",
+			},
+			"updated_at": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Timestamp when the group was last updated (RFC3339).",
+			},
+		},
+	}
+}
+
+func dataSourceGroupRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client.Client)
+
+	groups, err := c.GetWorkspaceGroups(ctx, c.WorkspaceUUID)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("failed to list groups: %w", err))
+	}
+	objects := make([]namedObject, 0, len(groups))
+	for _, g := range groups {
+		objects = append(objects, namedObject{Name: g.Name, Uuid: g.Uuid})
+	}
+
+	i, err := pickNamed("group", d.Get("id").(string), d.Get("name").(string), objects, false)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	group := groups[i]
+
+	description := ""
+	if group.Description != nil {
+		description = *group.Description
+	}
+
+	d.SetId(group.Uuid)
+	if err := d.Set("name", group.Name); err != nil {
+		return diag.FromErr(fmt.Errorf("failed to set name: %w", err))
+	}
+	if err := d.Set("description", description); err != nil {
+		return diag.FromErr(fmt.Errorf("failed to set description: %w", err))
+	}
+	if err := d.Set("created_at", group.CreatedAt.Format(time.RFC3339)); err != nil {
+		return diag.FromErr(fmt.Errorf("failed to set created_at: %w", err))
+	}
+	if err := d.Set("updated_at", group.UpdatedAt.Format(time.RFC3339)); err != nil {
+		return diag.FromErr(fmt.Errorf("failed to set updated_at: %w", err))
+	}
+	return nil
+}
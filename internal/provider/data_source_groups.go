@@ -16,7 +16,7 @@ func dataSourceGroups() *schema.Resource {
 	return &schema.Resource{
 		Description: "Fetches a list of IAM groups within a specific Sotoon workspace.",
 		ReadContext: dataSourceGroupsRead,
-		Schema: map[string]*schema.Schema{
+		Schema: withFilters(map[string]*schema.Schema{
 			"workspace_id": {
 				Type:        schema.TypeString,
 				Required:    true,
@@ -46,7 +46,7 @@ func dataSourceGroups() *schema.Resource {
 					},
 				},
 			},
-		},
+		}, nameFilterSchemas("groups")),
 	}
 }
 
@@ -61,6 +61,11 @@ func dataSourceGroupsRead(ctx context.Context, d *schema.ResourceData, meta inte
 		return diag.Errorf("Invalid workspace_id format: not a valid UUID")
 	}
 
+	names, err := newNameFilter(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
 	groups, err := c.GetWorkspaceGroups(ctx, &workspaceUUID)
 	if err != nil {
 		if strings.Contains(strings.ToLower(err.Error()), "forbidden") {
@@ -74,6 +79,9 @@ func dataSourceGroupsRead(ctx context.Context, d *schema.ResourceData, meta inte
 
 	groupList := make([]map[string]interface{}, 0, len(groups))
 	for _, group := range groups {
+		if !names.match(group.Name) {
+			continue
+		}
 		groupData := map[string]interface{}{
 			"id":   group.Uuid,
 			"name": group.Name,
@@ -0,0 +1,394 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	uuid "github.com/satori/go.uuid"
+
+	"github.com/sotoon/terraform-provider-sotoon/internal/client"
+)
+
+func dataSourceHygieneReport() *schema.Resource {
+	return &schema.Resource{
+		Description: "Reports IAM hygiene problems of the workspace: orphaned service users, empty groups, unused roles and rules, " +
+			"expired or expiring tokens and public keys shared by several principals. " +
+			"The API only lists the tokens and public keys of the user the provider authenticates as, and only when the provider `user_id` is set, so other users' credentials are not checked.",
+		ReadContext: dataSourceHygieneReportRead,
+		Schema: map[string]*schema.Schema{
+			"expires_within": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "30d",
+				ValidateFunc: validateLongDuration,
+				Description:  "Report tokens which have expired or expire within this duration, such as `14d` or `720h`.",
+			},
+			"orphan_service_users":   hygieneFindingSchema("Service users with no roles and no groups."),
+			"groups_without_members": hygieneFindingSchema("Groups with no users and no service users."),
+			"groups_without_roles":   hygieneFindingSchema("Groups with no roles."),
+			"unused_roles":           hygieneFindingSchema("Roles of the workspace bound to no user, service user or group."),
+			"unused_rules":           hygieneFindingSchema("Rules of the workspace held by no role."),
+			"stale_tokens": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "User and service user tokens which have expired or expire within `expires_within`.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Token UUID.",
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Token name.",
+						},
+						"owner": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The principal owning the token, as `user:<uuid>` or `service_user:<uuid>`.",
+						},
+						"expires_at": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Expiry of the token (RFC3339).",
+						},
+						"status": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "`expired` or `expiring`.",
+						},
+					},
+				},
+			},
+			"duplicate_public_keys": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Public keys registered by more than one principal. Keys are compared without their comment.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"key": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The key type and base64 blob.",
+						},
+						"key_ids": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "UUIDs of the public key entries.",
+						},
+						"owners": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "The principals holding the key, as `user:<uuid>` or `service_user:<uuid>`.",
+						},
+					},
+				},
+			},
+			"issue_count": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Total number of findings, convenient for `check` blocks.",
+			},
+		},
+	}
+}
+
+func hygieneFindingSchema(description string) *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Computed:    true,
+		Description: description,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"id": {
+					Type:        schema.TypeString,
+					Computed:    true,
+					Description: "UUID.",
+				},
+				"name": {
+					Type:        schema.TypeString,
+					Computed:    true,
+					Description: "Name.",
+				},
+			},
+		},
+	}
+}
+
+func hygieneFinding(id, name string) map[string]interface{} {
+	return map[string]interface{}{"id": id, "name": name}
+}
+
+func dataSourceHygieneReportRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client.Client)
+	now := time.Now()
+	window, err := parseLongDuration(d.Get("expires_within").(string))
+	if err != nil {
+		return diag.Errorf("invalid expires_within: %s", err)
+	}
+
+	orphans := []map[string]interface{}{}
+	staleTokens := []map[string]interface{}{}
+	keys := []ownedKey{}
+
+	serviceUsers, err := c.GetServiceUsers(ctx)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("failed to list service users: %w", err))
+	}
+	for _, su := range serviceUsers {
+		serviceUserUUID, err := uuid.FromString(su.Uuid)
+		if err != nil {
+			return diag.Errorf("invalid service user id %q returned by the API: %s", su.Uuid, err)
+		}
+		owner := graphKindServiceUser + ":" + su.Uuid
+
+		detail, err := c.GetWorkspaceServiceUserDetail(ctx, *c.WorkspaceUUID, serviceUserUUID)
+		if err != nil {
+			return diag.FromErr(fmt.Errorf("failed to get service user %s: %w", su.Name, err))
+		}
+		if len(detail.Roles) == 0 && len(detail.Groups) == 0 {
+			orphans = append(orphans, hygieneFinding(su.Uuid, su.Name))
+		}
+
+		tokens, err := c.GetWorkspaceServiceUserTokenList(ctx, &serviceUserUUID, c.WorkspaceUUID)
+		if err != nil {
+			return diag.FromErr(fmt.Errorf("failed to list tokens of service user %s: %w", su.Name, err))
+		}
+		for _, t := range *tokens {
+			if t.ExpiresAt == nil {
+				continue
+			}
+			if status := tokenExpiry(*t.ExpiresAt, now, window); status != "" {
+				staleTokens = append(staleTokens, map[string]interface{}{
+					"id":         t.Uuid,
+					"name":       t.Name,
+					"owner":      owner,
+					"expires_at": t.ExpiresAt.Format(time.RFC3339),
+					"status":     status,
+				})
+			}
+		}
+
+		publicKeys, err := c.GetWorkspaceServiceUserPublicKeyList(ctx, *c.WorkspaceUUID, serviceUserUUID)
+		if err != nil {
+			return diag.FromErr(fmt.Errorf("failed to list public keys of service user %s: %w", su.Name, err))
+		}
+		for _, k := range publicKeys {
+			keys = append(keys, ownedKey{ID: k.Uuid, Owner: owner, Key: publicKeyContent(k.Key, k.PublicKey)})
+		}
+	}
+
+	// the API lists tokens and public keys of the provider user only, and needs its user_id for that
+	if c.UserID != "" {
+		userTokens, err := c.GetAllMyUserTokenList(ctx)
+		if err != nil {
+			return diag.FromErr(fmt.Errorf("failed to list user tokens: %w", err))
+		}
+		for _, t := range userTokens {
+			if status := tokenExpiry(t.ExpiresAt, now, window); status != "" {
+				staleTokens = append(staleTokens, map[string]interface{}{
+					"id":         t.Uuid,
+					"name":       t.Name,
+					"owner":      graphKindUser + ":" + c.UserID,
+					"expires_at": t.ExpiresAt.Format(time.RFC3339),
+					"status":     status,
+				})
+			}
+		}
+		userKeys, err := c.GetAllMyUserPublicKeyList(ctx)
+		if err != nil {
+			return diag.FromErr(fmt.Errorf("failed to list user public keys: %w", err))
+		}
+		for _, k := range userKeys {
+			keys = append(keys, ownedKey{ID: k.Uuid, Owner: graphKindUser + ":" + c.UserID, Key: publicKeyContent(k.Key, k.PublicKey)})
+		}
+	}
+
+	withoutMembers := []map[string]interface{}{}
+	withoutRoles := []map[string]interface{}{}
+	groupRoles := map[string]struct{}{}
+	groups, err := c.GetWorkspaceGroups(ctx, c.WorkspaceUUID)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("failed to list groups: %w", err))
+	}
+	for _, g := range groups {
+		groupUUID, err := uuid.FromString(g.Uuid)
+		if err != nil {
+			return diag.Errorf("invalid group id %q returned by the API: %s", g.Uuid, err)
+		}
+		detail, err := c.GetWorkspaceGroupDetail(ctx, *c.WorkspaceUUID, groupUUID)
+		if err != nil {
+			return diag.FromErr(fmt.Errorf("failed to get group %s: %w", g.Name, err))
+		}
+		if detail.UsersNumber == 0 && detail.ServiceUsersNumber == 0 {
+			withoutMembers = append(withoutMembers, hygieneFinding(g.Uuid, g.Name))
+		}
+		if len(detail.Roles) == 0 {
+			withoutRoles = append(withoutRoles, hygieneFinding(g.Uuid, g.Name))
+		}
+		for _, r := range detail.Roles {
+			groupRoles[r.Uuid] = struct{}{}
+		}
+	}
+
+	unusedRoles := []map[string]interface{}{}
+	roles, err := c.GetWorkspaceRoles(ctx, c.Workspace)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("failed to list roles: %w", err))
+	}
+	for _, r := range roles {
+		if _, ok := groupRoles[r.Uuid]; ok {
+			continue
+		}
+		roleUUID, err := uuid.FromString(r.Uuid)
+		if err != nil {
+			return diag.Errorf("invalid role id %q returned by the API: %s", r.Uuid, err)
+		}
+		users, err := c.GetRoleUsers(ctx, &roleUUID)
+		if err != nil {
+			return diag.FromErr(fmt.Errorf("failed to list users of role %s: %w", r.Name, err))
+		}
+		serviceUsers, err := c.GetRoleServiceUsers(ctx, &roleUUID)
+		if err != nil {
+			return diag.FromErr(fmt.Errorf("failed to list service users of role %s: %w", r.Name, err))
+		}
+		if len(users) == 0 && len(serviceUsers) == 0 {
+			unusedRoles = append(unusedRoles, hygieneFinding(r.Uuid, r.Name))
+		}
+	}
+
+	unusedRules := []map[string]interface{}{}
+	rules, err := c.GetWorkspaceRules(ctx, c.Workspace)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("failed to list rules: %w", err))
+	}
+	for _, r := range rules {
+		ruleUUID, err := uuid.FromString(r.Uuid)
+		if err != nil {
+			return diag.Errorf("invalid rule id %q returned by the API: %s", r.Uuid, err)
+		}
+		holders, err := c.GetRuleRoles(ctx, &ruleUUID)
+		if err != nil {
+			return diag.FromErr(fmt.Errorf("failed to list roles of rule %s: %w", r.Name, err))
+		}
+		if len(holders) == 0 {
+			unusedRules = append(unusedRules, hygieneFinding(r.Uuid, r.Name))
+		}
+	}
+
+	duplicates := []map[string]interface{}{}
+	for _, dup := range duplicatePublicKeys(keys) {
+		duplicates = append(duplicates, map[string]interface{}{
+			"key":     dup.Key,
+			"key_ids": dup.IDs,
+			"owners":  dup.Owners,
+		})
+	}
+
+	findings := map[string][]map[string]interface{}{
+		"orphan_service_users":   orphans,
+		"groups_without_members": withoutMembers,
+		"groups_without_roles":   withoutRoles,
+		"unused_roles":           unusedRoles,
+		"unused_rules":           unusedRules,
+		"stale_tokens":           staleTokens,
+		"duplicate_public_keys":  duplicates,
+	}
+	count := 0
+	for key, list := range findings {
+		count += len(list)
+		if err := d.Set(key, list); err != nil {
+			return diag.FromErr(fmt.Errorf("failed to set %s: %w", key, err))
+		}
+	}
+	if err := d.Set("issue_count", count); err != nil {
+		return diag.FromErr(fmt.Errorf("failed to set issue_count: %w", err))
+	}
+
+	d.SetId("hygiene-report:" + c.WorkspaceUUID.String())
+	return nil
+}
+
+// the key content, from key or from public_key when key is empty
+func publicKeyContent(key, publicKey string) string {
+	if key != "" {
+		return key
+	}
+	return publicKey
+}
+
+// tokenExpiry classifies a token expiring at expiresAt as expired, or expiring when it expires within window.
+// It returns "" for healthy tokens and for tokens without expiry, which the API reports as a zero time.
+func tokenExpiry(expiresAt, now time.Time, window time.Duration) string {
+	switch {
+	case expiresAt.IsZero():
+		return ""
+	case !expiresAt.After(now):
+		return tokenStatusExpired
+	case expiresAt.Before(now.Add(window)):
+		return tokenStatusExpiring
+	}
+	return ""
+}
+
+// ownedKey is a public key with the principal it belongs to, such as user:<uuid>
+type ownedKey struct {
+	ID    string
+	Owner string
+	Key   string
+}
+
+// duplicateKey is a public key registered by more than one principal
+type duplicateKey struct {
+	Key    string
+	IDs    []string
+	Owners []string
+}
+
+// normalizes an SSH public key to its type and base64 blob, dropping the comment
+func normalizePublicKey(key string) string {
+	fields := strings.Fields(key)
+	if len(fields) > 2 {
+		fields = fields[:2]
+	}
+	return strings.Join(fields, " ")
+}
+
+// returns the keys registered by more than one principal, sorted by key
+func duplicatePublicKeys(keys []ownedKey) []duplicateKey {
+	byKey := map[string]*duplicateKey{}
+	for _, k := range keys {
+		n := normalizePublicKey(k.Key)
+		if n == "" {
+			continue
+		}
+		dup, ok := byKey[n]
+		if !ok {
+			dup = &duplicateKey{Key: n}
+			byKey[n] = dup
+		}
+		dup.IDs = append(dup.IDs, k.ID)
+		dup.Owners = append(dup.Owners, k.Owner)
+	}
+
+	out := []duplicateKey{}
+	for _, dup := range byKey {
+		dup.Owners = uniqueSorted(dup.Owners)
+		if len(dup.Owners) < 2 {
+			continue
+		}
+		dup.IDs = uniqueSorted(dup.IDs)
+		out = append(out, *dup)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out
+}
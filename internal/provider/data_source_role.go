@@ -0,0 +1,114 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	iam "github.com/sotoon/sotoon-sdk-go/sdk/core/iam_v1"
+
+	"github.com/sotoon/terraform-provider-sotoon/internal/client"
+)
+
+func dataSourceRole() *schema.Resource {
+	return &schema.Resource{
+		Description: "Looks up a single IAM role by name or UUID among the workspace and global roles. " +
+			"Fails when no role or several roles match.",
+		ReadContext: dataSourceRoleRead,
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"id", "name"},
+				Description:  "The UUID of the role.",
+			},
+			"name": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"id", "name"},
+				Description:  "The name of the role.",
+			},
+			"scope": nameScopeSchema("name"),
+			"description": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The description of the role.",
+			},
+			"service": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The service the role applies to.",
+			},
+			"global": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the role is a global role rather than a role of the workspace.",
+			},
+			"created_at": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Timestamp when the role was created (RFC3339).",
+			},
+			"updated_at": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Timestamp when the role was last updated (RFC3339).",
+			},
+		},
+	}
+}
+
+func dataSourceRoleRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client.Client)
+
+	roles := []iam.IamRole{}
+	global := []bool{}
+	objects := []namedObject{}
+	for _, ws := range scopeWorkspaces(c, d.Get("scope").(string)) {
+		list, err := c.GetWorkspaceRoles(ctx, ws)
+		if err != nil {
+			return diag.FromErr(fmt.Errorf("failed to list roles: %w", err))
+		}
+		for _, r := range list {
+			roles = append(roles, r)
+			global = append(global, ws == GlobalWorkspaceUUID.String())
+			objects = append(objects, namedObject{Name: r.Name, Uuid: r.Uuid})
+		}
+	}
+
+	i, err := pickNamed("role", d.Get("id").(string), d.Get("name").(string), objects, true)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	role := roles[i]
+
+	description := role.DescriptionEn
+	if role.Description != nil && *role.Description != "" {
+		description = *role.Description
+	}
+
+	d.SetId(role.Uuid)
+	if err := d.Set("name", role.Name); err != nil {
+		return diag.FromErr(fmt.Errorf("failed to set name: %w", err))
+	}
+	if err := d.Set("description", description); err != nil {
+		return diag.FromErr(fmt.Errorf("failed to set description: %w", err))
+	}
+	if err := d.Set("service", role.Service); err != nil {
+		return diag.FromErr(fmt.Errorf("failed to set service: %w", err))
+	}
+	if err := d.Set("global", global[i]); err != nil {
+		return diag.FromErr(fmt.Errorf("failed to set global: %w", err))
+	}
+	if err := d.Set("created_at", role.CreatedAt.Format(time.RFC3339)); err != nil {
+		return diag.FromErr(fmt.Errorf("failed to set created_at: %w", err))
+	}
+	if err := d.Set("updated_at", role.UpdatedAt.Format(time.RFC3339)); err != nil {
+		return diag.FromErr(fmt.Errorf("failed to set updated_at: %w", err))
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	uuid "github.com/satori/go.uuid"
+
+	"github.com/sotoon/terraform-provider-sotoon/internal/client"
+)
+
+func dataSourceRoleItems() *schema.Resource {
+	return &schema.Resource{
+		Description: "Describes the items a role accepts when it is bound, for example the bucket or cluster a role is scoped to. " +
+			"The API does not report which items are required, so none are marked as such.",
+		ReadContext: dataSourceRoleItemsRead,
+		Schema: map[string]*schema.Schema{
+			"role_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Role UUID.",
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Role name.",
+			},
+			"items_described": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the API describes the items of the role. When false, items bound with this role are not validated at plan time.",
+			},
+			"items": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Item keys the role accepts, sorted by key.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"key": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Item key.",
+						},
+						"allowed_values": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "Values the key may take. Empty when any value is accepted.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceRoleItemsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client.Client)
+
+	roleStr := d.Get("role_id").(string)
+	roleUUID, err := uuid.FromString(roleStr)
+	if err != nil {
+		return diag.Errorf("invalid role_id %q: %s", roleStr, err)
+	}
+
+	role, err := c.GetRole(ctx, &roleUUID)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("failed to get role %s: %w", roleUUID, err))
+	}
+
+	if err := d.Set("name", role.Name); err != nil {
+		return diag.FromErr(fmt.Errorf("failed to set name: %w", err))
+	}
+	if err := d.Set("items_described", role.PossibleItems != nil); err != nil {
+		return diag.FromErr(fmt.Errorf("failed to set items_described: %w", err))
+	}
+
+	itemList := []map[string]interface{}{}
+	if role.PossibleItems != nil {
+		keys := make([]string, 0, len(*role.PossibleItems))
+		for k := range *role.PossibleItems {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			itemList = append(itemList, map[string]interface{}{
+				"key":            k,
+				"allowed_values": (*role.PossibleItems)[k],
+			})
+		}
+	}
+	if err := d.Set("items", itemList); err != nil {
+		return diag.FromErr(fmt.Errorf("failed to set items: %w", err))
+	}
+
+	d.SetId("role-items:" + roleUUID.String())
+	return nil
+}
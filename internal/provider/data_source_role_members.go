@@ -0,0 +1,275 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	uuid "github.com/satori/go.uuid"
+	iam "github.com/sotoon/sotoon-sdk-go/sdk/core/iam_v1"
+
+	"github.com/sotoon/terraform-provider-sotoon/internal/client"
+)
+
+func dataSourceRoleMembers() *schema.Resource {
+	return &schema.Resource{
+		Description: "Lists who holds a role in the workspace: users and service users bound directly, with their items, and groups the role is bound to. " +
+			"The API has no call listing the groups of a role, so every group of the workspace is checked.",
+		ReadContext: dataSourceRoleMembersRead,
+		Schema: map[string]*schema.Schema{
+			"role_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Role UUID.",
+			},
+			"expand_groups": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "List the users of every group holding the role in `groups.*.users` and include them in `all_user_ids`.",
+			},
+			"users": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Users bound to the role directly, one entry per binding: a user bound several times with different items is listed once per binding.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "User UUID.",
+						},
+						"email": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "User email.",
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "User name.",
+						},
+						"is_suspended": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "Whether the user is suspended.",
+						},
+						"items": {
+							Type:        schema.TypeMap,
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "Items of the binding.",
+						},
+					},
+				},
+			},
+			"service_users": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Service users bound to the role directly, one entry per binding like `users`.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Service user UUID.",
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Service user name.",
+						},
+						"description": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Service user description.",
+						},
+						"items": {
+							Type:        schema.TypeMap,
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "Items of the binding.",
+						},
+					},
+				},
+			},
+			"groups": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Groups the role is bound to.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Group UUID.",
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Group name.",
+						},
+						"users": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Description: "Users of the group, only filled when `expand_groups` is `true`.",
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"id": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "User UUID.",
+									},
+									"email": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "User email.",
+									},
+									"name": {
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "User name.",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			"all_user_ids": {
+				Type:        schema.TypeSet,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "UUIDs of the users holding the role directly and, when `expand_groups` is `true`, through a group.",
+			},
+		},
+	}
+}
+
+func dataSourceRoleMembersRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client.Client)
+
+	roleStr := d.Get("role_id").(string)
+	roleUUID, err := uuid.FromString(roleStr)
+	if err != nil {
+		return diag.Errorf("invalid role_id %q: %s", roleStr, err)
+	}
+	expand := d.Get("expand_groups").(bool)
+
+	users, err := c.GetRoleUsers(ctx, &roleUUID)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("failed to list users of role: %w", err))
+	}
+	userIDs := []string{}
+	userList := make([]map[string]interface{}, 0, len(users))
+	for _, u := range users {
+		userIDs = append(userIDs, u.Uuid)
+		for _, items := range bindingRows(u.Items) {
+			userList = append(userList, map[string]interface{}{
+				"id":           u.Uuid,
+				"email":        u.Email,
+				"name":         u.Name,
+				"is_suspended": u.IsSuspended,
+				"items":        items,
+			})
+		}
+	}
+
+	serviceUsers, err := c.GetRoleServiceUsers(ctx, &roleUUID)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("failed to list service users of role: %w", err))
+	}
+	serviceUserList := make([]map[string]interface{}, 0, len(serviceUsers))
+	for _, su := range serviceUsers {
+		for _, items := range bindingRows(su.Items) {
+			serviceUserList = append(serviceUserList, map[string]interface{}{
+				"id":          su.Uuid,
+				"name":        su.Name,
+				"description": su.Description,
+				"items":       items,
+			})
+		}
+	}
+
+	groups, err := c.GetWorkspaceGroups(ctx, c.WorkspaceUUID)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("failed to list groups: %w", err))
+	}
+	rolesByGroup := make(map[string][]iam.IamRole, len(groups))
+	for _, g := range groups {
+		groupUUID, err := uuid.FromString(g.Uuid)
+		if err != nil {
+			return diag.Errorf("invalid group id %q returned by the API: %s", g.Uuid, err)
+		}
+		roles, err := c.GetWorkspaceGroupRoleList(ctx, c.WorkspaceUUID, &groupUUID)
+		if err != nil {
+			return diag.FromErr(fmt.Errorf("failed to list roles of group %s: %w", g.Name, err))
+		}
+		rolesByGroup[g.Uuid] = roles
+	}
+
+	groupList := make([]map[string]interface{}, 0)
+	for _, g := range groupsHoldingRole(groups, rolesByGroup, roleUUID.String()) {
+		groupUUID := uuid.FromStringOrNil(g.Uuid)
+		groupUsers := make([]map[string]interface{}, 0)
+		if expand {
+			members, err := c.GetWorkspaceGroupUsersList(ctx, c.WorkspaceUUID, &groupUUID)
+			if err != nil {
+				return diag.FromErr(fmt.Errorf("failed to list users of group %s: %w", g.Name, err))
+			}
+			for _, u := range members {
+				userIDs = append(userIDs, u.Uuid)
+				groupUsers = append(groupUsers, map[string]interface{}{
+					"id":    u.Uuid,
+					"email": u.Email,
+					"name":  u.Name,
+				})
+			}
+		}
+		groupList = append(groupList, map[string]interface{}{
+			"id":    g.Uuid,
+			"name":  g.Name,
+			"users": groupUsers,
+		})
+	}
+
+	if err := d.Set("users", userList); err != nil {
+		return diag.FromErr(fmt.Errorf("failed to set users: %w", err))
+	}
+	if err := d.Set("service_users", serviceUserList); err != nil {
+		return diag.FromErr(fmt.Errorf("failed to set service_users: %w", err))
+	}
+	if err := d.Set("groups", groupList); err != nil {
+		return diag.FromErr(fmt.Errorf("failed to set groups: %w", err))
+	}
+	if err := d.Set("all_user_ids", uniqueSorted(userIDs)); err != nil {
+		return diag.FromErr(fmt.Errorf("failed to set all_user_ids: %w", err))
+	}
+
+	d.SetId("role-members:" + c.WorkspaceUUID.String() + ":" + roleUUID.String())
+	return nil
+}
+
+// one items map per distinct binding of a member, a binding without items still yields one empty map
+func bindingRows(items []map[string]string) []map[string]string {
+	if entries := bindingEntries(items); len(entries) > 0 {
+		return entries
+	}
+	return []map[string]string{{}}
+}
+
+// groups, in their listed order, whose roles in rolesByGroup include roleID
+func groupsHoldingRole(groups []iam.IamGroup, rolesByGroup map[string][]iam.IamRole, roleID string) []iam.IamGroup {
+	out := []iam.IamGroup{}
+	for _, g := range groups {
+		for _, r := range rolesByGroup[g.Uuid] {
+			if strings.EqualFold(r.Uuid, roleID) {
+				out = append(out, g)
+				break
+			}
+		}
+	}
+	return out
+}
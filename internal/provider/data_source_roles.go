@@ -15,12 +15,31 @@ func dataSourceRoles() *schema.Resource {
 	return &schema.Resource{
 		Description: "Fetches a list of IAM roles within a specific Sotoon workspace and global roles.",
 		ReadContext: dataSourceRolesRead,
-		Schema: map[string]*schema.Schema{
+		Schema: withFilters(map[string]*schema.Schema{
 			"workspace_id": {
 				Type:        schema.TypeString,
 				Required:    true,
 				Description: "The UUID of the workspace to fetch roles from.",
 			},
+			"service": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return roles of this service. This filter is applied by the API.",
+			},
+			"global_only": {
+				Type:          schema.TypeBool,
+				Optional:      true,
+				Default:       false,
+				ConflictsWith: []string{"workspace_only"},
+				Description:   "Only fetch global roles, `roles` is left empty.",
+			},
+			"workspace_only": {
+				Type:          schema.TypeBool,
+				Optional:      true,
+				Default:       false,
+				ConflictsWith: []string{"global_only"},
+				Description:   "Only fetch the roles of the workspace, `global_roles` is left empty.",
+			},
 			"roles": {
 				Type:        schema.TypeList,
 				Computed:    true,
@@ -59,7 +78,7 @@ func dataSourceRoles() *schema.Resource {
 					},
 				},
 			},
-		},
+		}, nameFilterSchemas("roles")),
 	}
 }
 
@@ -73,25 +92,44 @@ func dataSourceRolesRead(ctx context.Context, d *schema.ResourceData, meta inter
 		return diag.FromErr(fmt.Errorf("invalid workspace_id format: not a valid UUID: %w", err))
 	}
 
-	// Get workspace-specific roles
-	roles, err := c.GetWorkspaceRoles(ctx, c.Workspace)
+	names, err := newNameFilter(d)
 	if err != nil {
-		return diag.Errorf("failed to list roles :%s", err)
+		return diag.FromErr(err)
 	}
+	service := d.Get("service").(string)
 
-	roleList := make([]map[string]interface{}, 0, len(roles))
-	for _, role := range roles {
-		roleList = append(roleList, map[string]interface{}{
-			"id":   role.Uuid,
-			"name": role.Name,
-		})
+	// Get workspace-specific roles
+	roleList := make([]map[string]interface{}, 0)
+	if !d.Get("global_only").(bool) {
+		roles, err := c.GetWorkspaceRolesOfService(ctx, c.Workspace, service)
+		if err != nil {
+			return diag.Errorf("failed to list roles :%s", err)
+		}
+
+		for _, role := range roles {
+			if !names.match(role.Name) {
+				continue
+			}
+			roleList = append(roleList, map[string]interface{}{
+				"id":   role.Uuid,
+				"name": role.Name,
+			})
+		}
 	}
 
 	if err := d.Set("roles", roleList); err != nil {
 		return diag.FromErr(fmt.Errorf("failed to set roles list: %w", err))
 	}
 
-	globalRoles, err := c.GetWorkspaceRoles(ctx, GlobalWorkspaceUUID.String())
+	if d.Get("workspace_only").(bool) {
+		if err := d.Set("global_roles", []map[string]interface{}{}); err != nil {
+			return diag.FromErr(fmt.Errorf("failed to set empty global roles list: %w", err))
+		}
+		d.SetId(workspaceID)
+		return nil
+	}
+
+	globalRoles, err := c.GetWorkspaceRolesOfService(ctx, GlobalWorkspaceUUID.String(), service)
 	if err != nil {
 		tflog.Error(ctx, "Failed to get global roles", map[string]interface{}{"error": err.Error()})
 		if err := d.Set("global_roles", []map[string]interface{}{}); err != nil {
@@ -100,6 +138,9 @@ func dataSourceRolesRead(ctx context.Context, d *schema.ResourceData, meta inter
 	} else {
 		globalRoleList := make([]map[string]interface{}, 0, len(globalRoles))
 		for _, role := range globalRoles {
+			if !names.match(role.Name) {
+				continue
+			}
 			globalRoleList = append(globalRoleList, map[string]interface{}{
 				"id":   role.Uuid,
 				"name": role.Name,
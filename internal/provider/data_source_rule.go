@@ -0,0 +1,139 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	iam "github.com/sotoon/sotoon-sdk-go/sdk/core/iam_v1"
+
+	"github.com/sotoon/terraform-provider-sotoon/internal/client"
+)
+
+func dataSourceRule() *schema.Resource {
+	return &schema.Resource{
+		Description: "Looks up a single IAM rule by name or UUID among the workspace and global rules. " +
+			"Fails when no rule or several rules match.",
+		ReadContext: dataSourceRuleRead,
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"id", "name"},
+				Description:  "The UUID of the rule.",
+			},
+			"name": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"id", "name"},
+				Description:  "The name of the rule.",
+			},
+			"scope": nameScopeSchema("name"),
+			"description": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The description of the rule.",
+			},
+			"actions": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The actions this rule grants or denies.",
+			},
+			"object": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The object this rule applies to.",
+			},
+			"service": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The service this rule applies to.",
+			},
+			"deny": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether this rule denies access (true) or allows (false).",
+			},
+			"global": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the rule is a global rule rather than a rule of the workspace.",
+			},
+			"created_at": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Timestamp when the rule was created (RFC3339).",
+			},
+			"updated_at": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Timestamp when the rule was last updated (RFC3339).",
+			},
+		},
+	}
+}
+
+func dataSourceRuleRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client.Client)
+
+	rules := []iam.IamRule{}
+	global := []bool{}
+	objects := []namedObject{}
+	for _, ws := range scopeWorkspaces(c, d.Get("scope").(string)) {
+		list, err := c.GetWorkspaceRules(ctx, ws)
+		if err != nil {
+			return diag.FromErr(fmt.Errorf("failed to list rules: %w", err))
+		}
+		for _, r := range list {
+			rules = append(rules, r)
+			global = append(global, ws == GlobalWorkspaceUUID.String())
+			objects = append(objects, namedObject{Name: r.Name, Uuid: r.Uuid})
+		}
+	}
+
+	i, err := pickNamed("rule", d.Get("id").(string), d.Get("name").(string), objects, true)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	rule := rules[i]
+
+	description := ""
+	if rule.Description != nil {
+		description = *rule.Description
+	}
+
+	d.SetId(rule.Uuid)
+	if err := d.Set("name", rule.Name); err != nil {
+		return diag.FromErr(fmt.Errorf("failed to set name: %w", err))
+	}
+	if err := d.Set("description", description); err != nil {
+		return diag.FromErr(fmt.Errorf("failed to set description: %w", err))
+	}
+	if err := d.Set("actions", rule.Actions); err != nil {
+		return diag.FromErr(fmt.Errorf("failed to set actions: %w", err))
+	}
+	if err := d.Set("object", rule.Object); err != nil {
+		return diag.FromErr(fmt.Errorf("failed to set object: %w", err))
+	}
+	if err := d.Set("service", rule.ServiceObject); err != nil {
+		return diag.FromErr(fmt.Errorf("failed to set service: %w", err))
+	}
+	if err := d.Set("deny", rule.Deny); err != nil {
+		return diag.FromErr(fmt.Errorf("failed to set deny: %w", err))
+	}
+	if err := d.Set("global", global[i]); err != nil {
+		return diag.FromErr(fmt.Errorf("failed to set global: %w", err))
+	}
+	if err := d.Set("created_at", rule.CreatedAt.Format(time.RFC3339)); err != nil {
+		return diag.FromErr(fmt.Errorf("failed to set created_at: %w", err))
+	}
+	if err := d.Set("updated_at", rule.UpdatedAt.Format(time.RFC3339)); err != nil {
+		return diag.FromErr(fmt.Errorf("failed to set updated_at: %w", err))
+	}
+	return nil
+}
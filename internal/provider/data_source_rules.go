@@ -3,6 +3,7 @@ package provider
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
@@ -16,12 +17,22 @@ func dataSourceRules() *schema.Resource {
 	return &schema.Resource{
 		Description: "Fetches a list of IAM rules within a specific Sotoon workspace and global rules.",
 		ReadContext: dataSourceRulesRead,
-		Schema: map[string]*schema.Schema{
+		Schema: withFilters(map[string]*schema.Schema{
 			"workspace_id": {
 				Type:        schema.TypeString,
 				Required:    true,
 				Description: "The UUID of the workspace to fetch rules from.",
 			},
+			"action_contains": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return rules whose actions include this action. The match is case-insensitive.",
+			},
+			"object_prefix": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return rules whose object starts with this prefix.",
+			},
 			"rules": {
 				Type:        schema.TypeList,
 				Computed:    true,
@@ -92,7 +103,7 @@ func dataSourceRules() *schema.Resource {
 					},
 				},
 			},
-		},
+		}, nameFilterSchemas("rules")),
 	}
 }
 
@@ -106,6 +117,13 @@ func dataSourceRulesRead(ctx context.Context, d *schema.ResourceData, meta inter
 		return diag.Errorf("Invalid workspace_id format: not a valid UUID")
 	}
 
+	names, err := newNameFilter(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	actionContains := d.Get("action_contains").(string)
+	objectPrefix := d.Get("object_prefix").(string)
+
 	// Get workspace-specific rules
 	rules, err := c.GetWorkspaceRules(ctx, c.Workspace)
 	if err != nil {
@@ -114,6 +132,9 @@ func dataSourceRulesRead(ctx context.Context, d *schema.ResourceData, meta inter
 
 	ruleList := make([]map[string]interface{}, 0, len(rules))
 	for _, rule := range rules {
+		if !names.match(rule.Name) || !ruleMatches(rule.Actions, rule.Object, actionContains, objectPrefix) {
+			continue
+		}
 		actions := make([]interface{}, len(rule.Actions))
 		for i, a := range rule.Actions {
 			actions[i] = a
@@ -141,6 +162,9 @@ func dataSourceRulesRead(ctx context.Context, d *schema.ResourceData, meta inter
 	} else {
 		globalRuleList := make([]map[string]interface{}, 0, len(globalRules))
 		for _, rule := range globalRules {
+			if !names.match(rule.Name) || !ruleMatches(rule.Actions, rule.Object, actionContains, objectPrefix) {
+				continue
+			}
 			actions := make([]interface{}, len(rule.Actions))
 			for i, a := range rule.Actions {
 				actions[i] = a
@@ -163,3 +187,19 @@ func dataSourceRulesRead(ctx context.Context, d *schema.ResourceData, meta inter
 	d.SetId(workspaceID)
 	return nil
 }
+
+// reports whether a rule passes the action_contains and object_prefix filters, empty filters match everything
+func ruleMatches(actions []string, object, actionContains, objectPrefix string) bool {
+	if !strings.HasPrefix(object, objectPrefix) {
+		return false
+	}
+	if actionContains == "" {
+		return true
+	}
+	for _, a := range actions {
+		if strings.EqualFold(a, actionContains) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"github.com/sotoon/terraform-provider-sotoon/internal/client"
+)
+
+func dataSourceServiceUser() *schema.Resource {
+	return &schema.Resource{
+		Description: "Looks up a single service user of the workspace by name or UUID. Fails when no service user or several service users match.",
+		ReadContext: dataSourceServiceUserRead,
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"id", "name"},
+				Description:  "The UUID of the service user.",
+			},
+			"name": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"id", "name"},
+				Description:  "The name of the service user.",
+			},
+			"description": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The description of the service user.",
+			},
+			"created_at": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Timestamp when the service user was created (RFC3339).",
+			},
+			"updated_at": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Timestamp when the service user was last updated (RFC3339).",
+			},
+		},
+	}
+}
+
+func dataSourceServiceUserRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client.Client)
+
+	serviceUsers, err := c.GetServiceUsers(ctx)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("failed to list service users: %w", err))
+	}
+	objects := make([]namedObject, 0, len(serviceUsers))
+	for _, su := range serviceUsers {
+		objects = append(objects, namedObject{Name: su.Name, Uuid: su.Uuid})
+	}
+
+	i, err := pickNamed("service user", d.Get("id").(string), d.Get("name").(string), objects, false)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	serviceUser := serviceUsers[i]
+
+	d.SetId(serviceUser.Uuid)
+	if err := d.Set("name", serviceUser.Name); err != nil {
+		return diag.FromErr(fmt.Errorf("failed to set name: %w", err))
+	}
+	if err := d.Set("description", serviceUser.Description); err != nil {
+		return diag.FromErr(fmt.Errorf("failed to set description: %w", err))
+	}
+	if err := d.Set("created_at", serviceUser.CreatedAt.Format(time.RFC3339)); err != nil {
+		return diag.FromErr(fmt.Errorf("failed to set created_at: %w", err))
+	}
+	if err := d.Set("updated_at", serviceUser.UpdatedAt.Format(time.RFC3339)); err != nil {
+		return diag.FromErr(fmt.Errorf("failed to set updated_at: %w", err))
+	}
+	return nil
+}
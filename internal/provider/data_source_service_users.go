@@ -13,7 +13,7 @@ import (
 func dataSourceServiceUsers() *schema.Resource {
 	return &schema.Resource{
 		ReadContext: dataSourceServiceUsersRead,
-		Schema: map[string]*schema.Schema{
+		Schema: withFilters(map[string]*schema.Schema{
 			"id": {
 				Type:     schema.TypeString,
 				Computed: true,
@@ -29,13 +29,18 @@ func dataSourceServiceUsers() *schema.Resource {
 					},
 				},
 			},
-		},
+		}, nameFilterSchemas("service users")),
 	}
 }
 
 func dataSourceServiceUsersRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client.Client)
 
+	names, err := newNameFilter(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
 	list, err := c.GetServiceUsers(ctx)
 	if err != nil {
 		return diag.FromErr(err)
@@ -43,7 +48,7 @@ func dataSourceServiceUsersRead(ctx context.Context, d *schema.ResourceData, met
 
 	out := make([]map[string]interface{}, 0, len(list))
 	for _, su := range list {
-		if su.Uuid == "" {
+		if su.Uuid == "" || !names.match(su.Name) {
 			continue
 		}
 		out = append(out, map[string]interface{}{
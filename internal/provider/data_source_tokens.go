@@ -0,0 +1,248 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	uuid "github.com/satori/go.uuid"
+
+	"github.com/sotoon/terraform-provider-sotoon/internal/client"
+)
+
+func dataSourceTokens() *schema.Resource {
+	return &schema.Resource{
+		Description: "Lists the tokens of every service user of the workspace and the tokens of the user the provider authenticates as. " +
+			"Service users are queried concurrently. The API only lists the tokens of the provider's own user, and only when the provider `user_id` is set.",
+		ReadContext: dataSourceTokensRead,
+		Schema: map[string]*schema.Schema{
+			"expires_within": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateLongDuration,
+				Description:  "Only return tokens which have expired or expire within this duration, such as `90d` or `720h`.",
+			},
+			"never_expires": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "Only return tokens without expiry when `true`, or only tokens with an expiry when `false`.",
+			},
+			"max_concurrency": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      8,
+				ValidateFunc: validation.IntAtLeast(1),
+				Description:  "Maximum number of service users queried at the same time.",
+			},
+			"tokens": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Matching tokens, sorted by owner and name.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Token UUID.",
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Token name.",
+						},
+						"owner_kind": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "`user` or `service_user`.",
+						},
+						"owner_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "UUID of the user or service user owning the token.",
+						},
+						"owner_name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the service user owning the token. Empty for user tokens.",
+						},
+						"expires_at": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Expiry of the token (RFC3339). Empty when the token never expires.",
+						},
+						"never_expires": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "Whether the token has no expiry.",
+						},
+						"expired": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "Whether the token has expired.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// inventoryToken is a token of sotoon_iam_tokens with a zero ExpiresAt when it never expires
+type inventoryToken struct {
+	ID        string
+	Name      string
+	OwnerKind string
+	OwnerID   string
+	OwnerName string
+	ExpiresAt time.Time
+}
+
+func dataSourceTokensRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client.Client)
+
+	filter := tokenFilter{}
+	if raw, ok := d.GetOk("expires_within"); ok {
+		within, err := parseLongDuration(raw.(string))
+		if err != nil {
+			return diag.Errorf("invalid expires_within: %s", err)
+		}
+		filter.within = &within
+	}
+	if never := d.GetRawConfig().GetAttr("never_expires"); !never.IsNull() {
+		v := never.True()
+		filter.neverExpires = &v
+	}
+
+	serviceUsers, err := c.GetServiceUsers(ctx)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("failed to list service users: %w", err))
+	}
+
+	perServiceUser := make([][]inventoryToken, len(serviceUsers))
+	err = forEachConcurrently(len(serviceUsers), d.Get("max_concurrency").(int), func(i int) error {
+		su := serviceUsers[i]
+		serviceUserUUID, err := uuid.FromString(su.Uuid)
+		if err != nil {
+			return fmt.Errorf("invalid service user id %q returned by the API: %w", su.Uuid, err)
+		}
+		list, err := c.GetWorkspaceServiceUserTokenList(ctx, &serviceUserUUID, c.WorkspaceUUID)
+		if err != nil {
+			return fmt.Errorf("failed to list tokens of service user %s: %w", su.Name, err)
+		}
+		if list == nil {
+			return nil
+		}
+		for _, t := range *list {
+			token := inventoryToken{ID: t.Uuid, Name: t.Name, OwnerKind: graphKindServiceUser, OwnerID: su.Uuid, OwnerName: su.Name}
+			if t.ExpiresAt != nil {
+				token.ExpiresAt = *t.ExpiresAt
+			}
+			perServiceUser[i] = append(perServiceUser[i], token)
+		}
+		return nil
+	})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	tokens := []inventoryToken{}
+	for _, list := range perServiceUser {
+		tokens = append(tokens, list...)
+	}
+	if c.UserID != "" {
+		userTokens, err := c.GetAllMyUserTokenList(ctx)
+		if err != nil {
+			return diag.FromErr(fmt.Errorf("failed to list user tokens: %w", err))
+		}
+		for _, t := range userTokens {
+			tokens = append(tokens, inventoryToken{ID: t.Uuid, Name: t.Name, OwnerKind: graphKindUser, OwnerID: c.UserID, ExpiresAt: t.ExpiresAt})
+		}
+	}
+	sort.SliceStable(tokens, func(i, j int) bool {
+		if tokens[i].OwnerKind != tokens[j].OwnerKind {
+			return tokens[i].OwnerKind < tokens[j].OwnerKind
+		}
+		if tokens[i].OwnerID != tokens[j].OwnerID {
+			return tokens[i].OwnerID < tokens[j].OwnerID
+		}
+		return tokens[i].Name < tokens[j].Name
+	})
+
+	now := time.Now()
+	out := make([]map[string]interface{}, 0, len(tokens))
+	for _, t := range tokens {
+		if t.ID == "" || !filter.match(t.ExpiresAt, now) {
+			continue
+		}
+		expiresAt := ""
+		if !t.ExpiresAt.IsZero() {
+			expiresAt = t.ExpiresAt.Format(time.RFC3339)
+		}
+		out = append(out, map[string]interface{}{
+			"id":            t.ID,
+			"name":          t.Name,
+			"owner_kind":    t.OwnerKind,
+			"owner_id":      t.OwnerID,
+			"owner_name":    t.OwnerName,
+			"expires_at":    expiresAt,
+			"never_expires": t.ExpiresAt.IsZero(),
+			"expired":       !t.ExpiresAt.IsZero() && !t.ExpiresAt.After(now),
+		})
+	}
+	if err := d.Set("tokens", out); err != nil {
+		return diag.FromErr(fmt.Errorf("failed to set tokens: %w", err))
+	}
+
+	d.SetId("tokens:" + c.WorkspaceUUID.String())
+	return nil
+}
+
+// forEachConcurrently calls fn for every index below n with at most limit calls in flight. It waits for every
+// call and returns the error of the lowest failing index, so the error does not depend on scheduling.
+func forEachConcurrently(n, limit int, fn func(i int) error) error {
+	if limit < 1 {
+		limit = 1
+	}
+	errs := make([]error, n)
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			errs[i] = fn(i)
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tokenFilter holds the expires_within and never_expires filters of sotoon_iam_tokens, a zero expiry means
+// the token never expires
+type tokenFilter struct {
+	within       *time.Duration
+	neverExpires *bool
+}
+
+func (f tokenFilter) match(expiresAt, now time.Time) bool {
+	never := expiresAt.IsZero()
+	if f.neverExpires != nil && *f.neverExpires != never {
+		return false
+	}
+	if f.within != nil && (never || !expiresAt.Before(now.Add(*f.within))) {
+		return false
+	}
+	return true
+}
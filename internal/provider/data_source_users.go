@@ -9,6 +9,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	uuid "github.com/satori/go.uuid"
+	iam "github.com/sotoon/sotoon-sdk-go/sdk/core/iam_v1"
 	"github.com/sotoon/terraform-provider-sotoon/internal/client"
 )
 
@@ -16,12 +17,27 @@ func dataSourceUsers() *schema.Resource {
 	return &schema.Resource{
 		Description: "Fetches a list of IAM users within a specific Sotoon workspace.",
 		ReadContext: dataSourceUsersRead,
-		Schema: map[string]*schema.Schema{
+		Schema: withFilters(map[string]*schema.Schema{
 			"workspace_id": {
 				Type:        schema.TypeString,
 				Required:    true,
 				Description: "The UUID of the workspace to fetch users from.",
 			},
+			"email": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return the user with this exact email. This filter is applied by the API.",
+			},
+			"email_domain": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return users whose email belongs to this domain, such as `example.com`. The match is case-insensitive.",
+			},
+			"is_suspended": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Description: "Only return suspended users when `true`, or only users who are not suspended when `false`.",
+			},
 			"users": {
 				Type:        schema.TypeList,
 				Computed:    true,
@@ -51,7 +67,7 @@ func dataSourceUsers() *schema.Resource {
 					},
 				},
 			},
-		},
+		}, nameFilterSchemas("users")),
 	}
 }
 
@@ -66,7 +82,17 @@ func dataSourceUsersRead(ctx context.Context, d *schema.ResourceData, meta inter
 		return diag.Errorf("Invalid workspace_id format: not a valid UUID")
 	}
 
-	users, err := c.GetWorkspaceUsers(ctx, &workspaceUUID)
+	names, err := newNameFilter(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	var users []iam.IamUser
+	if email, ok := d.GetOk("email"); ok {
+		users, err = c.GetWorkspaceUsersByEmail(ctx, &workspaceUUID, email.(string))
+	} else {
+		users, err = c.GetWorkspaceUsers(ctx, &workspaceUUID)
+	}
 	if err != nil {
 		if strings.Contains(strings.ToLower(err.Error()), "forbidden") {
 			return diag.Errorf(
@@ -78,12 +104,23 @@ func dataSourceUsersRead(ctx context.Context, d *schema.ResourceData, meta inter
 	}
 
 	userList := make([]map[string]interface{}, 0, len(users))
+	domain := d.Get("email_domain").(string)
+	suspended := d.GetRawConfig().GetAttr("is_suspended")
 	for _, user := range users {
+		if !names.match(user.Name) {
+			continue
+		}
+		if domain != "" && !emailInDomain(user.Email, domain) {
+			continue
+		}
+		if !suspended.IsNull() && user.IsSuspended != suspended.True() {
+			continue
+		}
 		userData := map[string]interface{}{
-			"id":    user.Uuid,
-			"email": user.Email,
-			"name":  user.Name,
-			// "is_suspended": user.IsSuspended,
+			"id":           user.Uuid,
+			"email":        user.Email,
+			"name":         user.Name,
+			"is_suspended": user.IsSuspended,
 		}
 		userList = append(userList, userData)
 	}
@@ -100,3 +137,9 @@ func dataSourceUsersRead(ctx context.Context, d *schema.ResourceData, meta inter
 func createDataSourceUsers_ID(workspaceID string) string {
 	return "users_" + workspaceID
 }
+
+// reports whether email belongs to domain, which may be given with or without the leading @
+func emailInDomain(email, domain string) bool {
+	domain = strings.TrimPrefix(strings.ToLower(domain), "@")
+	return strings.HasSuffix(strings.ToLower(email), "@"+domain)
+}
@@ -1,14 +1,24 @@
 package provider
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"reflect"
+	"regexp"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	uuid "github.com/satori/go.uuid"
+	"github.com/sotoon/terraform-provider-sotoon/internal/client"
 )
 
 // create sorted and unique array of uuids
@@ -66,6 +76,23 @@ func parseTwoPartID(id string) (uuid.UUID, uuid.UUID, error) {
 	return a, b, nil
 }
 
+// importer for "a/b" ids, stores both parts into the given attributes
+func importTwoPartID(first, second string) schema.StateContextFunc {
+	return func(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+		a, b, err := parseTwoPartID(d.Id())
+		if err != nil {
+			return nil, fmt.Errorf("import id must be <%s>/<%s>: %w", first, second, err)
+		}
+		if err := d.Set(first, a.String()); err != nil {
+			return nil, fmt.Errorf("failed to set %s: %w", first, err)
+		}
+		if err := d.Set(second, b.String()); err != nil {
+			return nil, fmt.Errorf("failed to set %s: %w", second, err)
+		}
+		return []*schema.ResourceData{d}, nil
+	}
+}
+
 func toSet(xs []string) map[string]struct{} {
 	m := make(map[string]struct{}, len(xs))
 	for _, x := range xs {
@@ -101,3 +128,1010 @@ func diff(a, b map[string]struct{}) []string {
 	}
 	return uniqueSorted(out)
 }
+
+// returns the ids a binding resource reports after read; in exclusive mode the remote set is authoritative,
+// otherwise only the locally managed ids which still exist remotely are kept
+func effectiveIDs(local, remote []string, exclusive bool) []string {
+	if exclusive {
+		return uniqueSorted(remote)
+	}
+	return uniqueSorted(setKeys(intersect(toSet(local), toSet(remote))))
+}
+
+// returns the ids to unbind when moving a binding from old to desired; in exclusive mode every remote id
+// which is not desired is unbound as well
+func idsToRemove(old, desired, remote []string, exclusive bool) []string {
+	bound := toSet(old)
+	if exclusive {
+		for _, id := range remote {
+			bound[id] = struct{}{}
+		}
+	}
+	return diff(bound, toSet(desired))
+}
+
+// members to add and to remove when a binding moves to desired in place: desired ids missing remotely are
+// added, managed ids no longer desired are removed, and in exclusive mode every other remote id as well
+func memberDelta(desired, remote, managed []string, exclusive bool) (toAdd, toRemove []string) {
+	return diff(toSet(desired), toSet(remote)), idsToRemove(managed, desired, remote, exclusive)
+}
+
+// canonical form of the items of a binding: empty entries the api may return are dropped and the rest
+// are ordered by their content, so neither shows up as drift
+func normalizeItems(items []map[string]string) []map[string]string {
+	out := make([]map[string]string, 0, len(items))
+	for _, m := range items {
+		if len(m) > 0 {
+			out = append(out, m)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return fmt.Sprint(out[i]) < fmt.Sprint(out[j])
+	})
+	return out
+}
+
+// the distinct bindings of a member, the api returns them as a list with one map per binding
+func bindingEntries(items []map[string]string) []map[string]string {
+	out := []map[string]string{}
+	seen := map[string]struct{}{}
+	for _, m := range normalizeItems(items) {
+		key := fmt.Sprint(m)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		out = append(out, m)
+	}
+	return out
+}
+
+// the ids whose drifted items a binding resource rebinds: in exclusive mode all of them, otherwise only
+// those it bound itself, so bindings which existed before the resource are left alone
+func rebindableIDs(ids, managed []string, exclusive bool) []string {
+	if exclusive {
+		return uniqueSorted(ids)
+	}
+	return uniqueSorted(setKeys(intersect(toSet(ids), toSet(managed))))
+}
+
+// items of a single binding; a member bound more than once with different items has no single items map,
+// which is reported as an error instead of merging the bindings
+func bindingItems(items []map[string]string) (map[string]string, error) {
+	entries := bindingEntries(items)
+	switch len(entries) {
+	case 0:
+		return map[string]string{}, nil
+	case 1:
+		return entries[0], nil
+	}
+	return nil, fmt.Errorf("bound %d times with different items %v, remove the extra bindings outside of Terraform", len(entries), entries)
+}
+
+// returns the ids a binding resource owns after an update added and removed some of them
+func managedAfter(managed, added, removed []string) []string {
+	m := toSet(managed)
+	for _, id := range added {
+		m[id] = struct{}{}
+	}
+	for _, id := range removed {
+		delete(m, id)
+	}
+	return uniqueSorted(setKeys(m))
+}
+
+// states written before binding resources tracked the ids they added have no value for key; such
+// bindings are treated as owning every id, which was the behaviour at the time
+func tracksManagedIDs(d *schema.ResourceData, key string) bool {
+	raw := d.GetRawState()
+	if raw.IsNull() || !raw.IsKnown() {
+		return true
+	}
+	if !raw.Type().HasAttribute(key) {
+		return false
+	}
+	return !raw.GetAttr(key).IsNull()
+}
+
+// converts a schema map into the string map the bind apis expect
+func stringMap(raw map[string]interface{}) map[string]string {
+	out := make(map[string]string, len(raw))
+	for k, v := range raw {
+		out[k] = fmt.Sprintf("%v", v)
+	}
+	return out
+}
+
+// checks the items read back for a binding against the single items map it was bound with
+func itemsMatch(desired map[string]string, remote []map[string]string) bool {
+	n := normalizeItems(remote)
+	if len(desired) == 0 {
+		return len(n) == 0
+	}
+	if len(n) != 1 || len(n[0]) != len(desired) {
+		return false
+	}
+	for k, v := range desired {
+		if rv, ok := n[0][k]; !ok || rv != v {
+			return false
+		}
+	}
+	return true
+}
+
+// returns the ids, sorted, whose items read back from the api differ from the desired items
+func driftedItemIDs(ids []string, remote map[string][]map[string]string, desired map[string]string) []string {
+	out := []string{}
+	for _, id := range ids {
+		if !itemsMatch(desired, remote[id]) {
+			out = append(out, id)
+		}
+	}
+	return uniqueSorted(out)
+}
+
+// nested block binding one member with its own items, an alternative to the id sets named by idsKeys with
+// shared items
+func roleBindingSchema(memberDescription string, idsKeys ...string) *schema.Schema {
+	s := &schema.Schema{
+		Type:        schema.TypeSet,
+		Optional:    true,
+		MinItems:    1,
+		Description: "Per-member bindings, each with its own items. Conflicts with `" + strings.Join(idsKeys, "`, `") + "` and `items`.",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"id": {
+					Type:        schema.TypeString,
+					Required:    true,
+					Description: memberDescription,
+				},
+				"items": {
+					Type:        schema.TypeMap,
+					Optional:    true,
+					Elem:        &schema.Schema{Type: schema.TypeString},
+					Description: "Key/value items for this member only.",
+				},
+			},
+		},
+	}
+	if len(idsKeys) == 1 {
+		s.ExactlyOneOf = []string{idsKeys[0], "binding"}
+	} else {
+		s.AtLeastOneOf = append(append([]string{}, idsKeys...), "binding")
+		s.ConflictsWith = idsKeys
+	}
+	return s
+}
+
+// desired bindings keyed by member id, taken from the binding blocks or from idsKey with the shared items
+func desiredBindings(d *schema.ResourceData, idsKey string) map[string]map[string]string {
+	out := map[string]map[string]string{}
+	if raw, ok := d.GetOk("binding"); ok {
+		for _, v := range raw.(*schema.Set).List() {
+			b := v.(map[string]interface{})
+			items, _ := b["items"].(map[string]interface{})
+			out[b["id"].(string)] = stringMap(items)
+		}
+		return out
+	}
+	shared := stringMap(d.Get("items").(map[string]interface{}))
+	for _, id := range fromSchemaSetToStrings(d.Get(idsKey).(*schema.Set)) {
+		out[id] = shared
+	}
+	return out
+}
+
+// withSharedBindings adds ids which are not in desired yet with the shared items, for members given by email or name
+func withSharedBindings(d *schema.ResourceData, desired map[string]map[string]string, ids []string) map[string]map[string]string {
+	shared := stringMap(d.Get("items").(map[string]interface{}))
+	for _, id := range ids {
+		if _, ok := desired[id]; !ok {
+			desired[id] = shared
+		}
+	}
+	return desired
+}
+
+func bindingIDs(bindings map[string]map[string]string) []string {
+	out := make([]string, 0, len(bindings))
+	for id := range bindings {
+		out = append(out, id)
+	}
+	return uniqueSorted(out)
+}
+
+// items for each of ids in the same order, nil when none of them carries items
+func bindingItemsList(ids []string, bindings map[string]map[string]string) []map[string]string {
+	out := make([]map[string]string, 0, len(ids))
+	withItems := false
+	for _, id := range ids {
+		items := bindings[id]
+		if items == nil {
+			items = map[string]string{}
+		}
+		withItems = withItems || len(items) > 0
+		out = append(out, items)
+	}
+	if !withItems {
+		return nil
+	}
+	return out
+}
+
+// returns the bound ids, sorted, whose items read back from the api differ from their desired items
+func driftedBindingIDs(ids []string, remote map[string][]map[string]string, desired map[string]map[string]string) []string {
+	out := []string{}
+	for _, id := range ids {
+		if !itemsMatch(desired[id], remote[id]) {
+			out = append(out, id)
+		}
+	}
+	return uniqueSorted(out)
+}
+
+// items reported for the shared items of drifted members: those of the first drifted member, with a warning
+// listing every drifted member when their items disagree, so drift on the others is not hidden
+func driftedSharedItems(drifted []string, remote map[string][]map[string]string) (map[string]string, diag.Diagnostics) {
+	var first map[string]string
+	disagree := false
+	details := make([]string, 0, len(drifted))
+	for _, id := range drifted {
+		items, err := bindingItems(remote[id])
+		if err != nil {
+			return nil, diag.Errorf("%s is %s", id, err)
+		}
+		if first == nil {
+			first = items
+		} else if !reflect.DeepEqual(first, items) {
+			disagree = true
+		}
+		details = append(details, fmt.Sprintf("%s: %v", id, items))
+	}
+	if !disagree {
+		return first, nil
+	}
+	return first, diag.Diagnostics{{
+		Severity: diag.Warning,
+		Summary:  "Binding items changed outside of Terraform",
+		Detail:   "These members are bound with other items than `items`, the next apply binds them with `items` again:\n" + strings.Join(details, "\n"),
+	}}
+}
+
+// binding blocks for state; desired items are kept while they match what the api returned, and always for
+// members which are not rebindable
+func flattenBindings(ids, rebindable []string, desired map[string]map[string]string, remote map[string][]map[string]string) ([]interface{}, error) {
+	out := make([]interface{}, 0, len(ids))
+	watched := toSet(rebindable)
+	for _, id := range uniqueSorted(ids) {
+		items := desired[id]
+		if _, ok := watched[id]; ok && !itemsMatch(items, remote[id]) {
+			var err error
+			if items, err = bindingItems(remote[id]); err != nil {
+				return nil, fmt.Errorf("%s is %w", id, err)
+			}
+		}
+		if items == nil {
+			items = map[string]string{}
+		}
+		out = append(out, map[string]interface{}{"id": id, "items": items})
+	}
+	return out, nil
+}
+
+// roleItems pairs a role with items it is about to be bound with
+type roleItems struct {
+	roleID string
+	items  map[string]string
+}
+
+// validates items against the item schema of a role; a nil schema means the api does not describe the
+// role and nothing is checked
+func validateRoleItems(roleID string, possible *map[string][]string, items map[string]string) error {
+	if possible == nil {
+		return nil
+	}
+	accepted := make([]string, 0, len(*possible))
+	for k := range *possible {
+		accepted = append(accepted, k)
+	}
+	accepted = uniqueSorted(accepted)
+
+	keys := make([]string, 0, len(items))
+	for k := range items {
+		keys = append(keys, k)
+	}
+	for _, k := range uniqueSorted(keys) {
+		allowed, ok := (*possible)[k]
+		if !ok {
+			if len(accepted) == 0 {
+				return fmt.Errorf("role %s does not accept items, got %q", roleID, k)
+			}
+			return fmt.Errorf("role %s does not accept item %q, accepted items: %s", roleID, k, strings.Join(accepted, ", "))
+		}
+		if len(allowed) == 0 {
+			continue
+		}
+		found := false
+		for _, v := range allowed {
+			if v == items[k] {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("item %q of role %s must be one of %s, got %q", k, roleID, strings.Join(allowed, ", "), items[k])
+		}
+	}
+	return nil
+}
+
+// role/items pairs of the planned bindings; roleKey names the role attribute of user and service user
+// bindings and is empty when the members are roles themselves, idsKey is empty for single bindings.
+// Returns false while any of them is unknown.
+func plannedRoleItems(d *schema.ResourceDiff, roleKey, idsKey, resolvedKey string) ([]roleItems, bool) {
+	for _, k := range bindingItemKeys(roleKey, idsKey, resolvedKey) {
+		if !d.NewValueKnown(k) {
+			return nil, false
+		}
+	}
+
+	bindings := map[string]map[string]string{}
+	if idsKey == "" {
+		bindings[""] = stringMap(d.Get("items").(map[string]interface{}))
+	} else if raw := d.Get("binding").(*schema.Set); raw.Len() > 0 {
+		for _, v := range raw.List() {
+			b := v.(map[string]interface{})
+			items, _ := b["items"].(map[string]interface{})
+			bindings[b["id"].(string)] = stringMap(items)
+		}
+	} else {
+		shared := stringMap(d.Get("items").(map[string]interface{}))
+		for _, id := range fromSchemaSetToStrings(d.Get(idsKey).(*schema.Set)) {
+			bindings[id] = shared
+		}
+		// roles given by name are resolved into resolvedKey by an earlier CustomizeDiff step
+		if resolvedKey != "" && roleKey == "" {
+			for _, id := range stringMap(d.Get(resolvedKey).(map[string]interface{})) {
+				bindings[id] = shared
+			}
+		}
+		// members given only by email share the role, their items are checked once
+		if len(bindings) == 0 && roleKey != "" {
+			bindings[""] = shared
+		}
+	}
+
+	out := make([]roleItems, 0, len(bindings))
+	for _, id := range bindingIDs(bindings) {
+		roleID := id
+		if roleKey != "" {
+			roleID = d.Get(roleKey).(string)
+		}
+		out = append(out, roleItems{roleID: roleID, items: bindings[id]})
+	}
+	return out, true
+}
+
+// attributes which decide the role/items pairs of a binding resource
+func bindingItemKeys(roleKey, idsKey, resolvedKey string) []string {
+	keys := []string{"items"}
+	if roleKey != "" {
+		keys = append(keys, roleKey)
+	}
+	if idsKey != "" {
+		keys = append(keys, idsKey, "binding")
+	}
+	if resolvedKey != "" {
+		keys = append(keys, resolvedKey)
+	}
+	return keys
+}
+
+// CustomizeDiff step rejecting binding items the bound roles do not accept, so typos fail at plan time
+// instead of at apply or binding an unscoped role. Roles given by name are checked through resolvedKey, which
+// an earlier step fills; roles which cannot be read, or are still unknown at plan time, are skipped.
+func validateBindingItems(roleKey, idsKey, resolvedKey string) schema.CustomizeDiffFunc {
+	return func(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+		if d.Id() != "" && !d.HasChanges(bindingItemKeys(roleKey, idsKey, resolvedKey)...) {
+			return nil
+		}
+		planned, known := plannedRoleItems(d, roleKey, idsKey, resolvedKey)
+		if !known {
+			return nil
+		}
+		c, ok := meta.(*client.Client)
+		if !ok || c == nil {
+			return nil
+		}
+
+		return checkPlannedItems(planned, func(roleUUID uuid.UUID) (*map[string][]string, error) {
+			role, err := c.GetRole(ctx, &roleUUID)
+			if err != nil {
+				tflog.Warn(ctx, "Skipping item validation, role could not be read", map[string]interface{}{"role_id": roleUUID.String(), "error": err.Error()})
+				return nil, nil
+			}
+			return role.PossibleItems, nil
+		})
+	}
+}
+
+// checks planned role/items pairs against the item schema of each role, which possibleItems looks up once
+// per role. Role ids which are not UUIDs are left to the apply and the other roles are still checked.
+func checkPlannedItems(planned []roleItems, possibleItems func(roleUUID uuid.UUID) (*map[string][]string, error)) error {
+	schemas := map[string]*map[string][]string{}
+	for _, p := range planned {
+		if len(p.items) == 0 {
+			continue
+		}
+		possible, seen := schemas[p.roleID]
+		if !seen {
+			roleUUID, err := uuid.FromString(p.roleID)
+			if err != nil {
+				continue
+			}
+			if possible, err = possibleItems(roleUUID); err != nil {
+				return err
+			}
+			schemas[p.roleID] = possible
+		}
+		if err := validateRoleItems(p.roleID, possible, p.items); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// schema validation for Go duration strings such as "30m" or "168h"
+func validateDuration(v interface{}, key string) ([]string, []error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, []error{fmt.Errorf("expected %s to be a string", key)}
+	}
+	if _, err := time.ParseDuration(s); err != nil {
+		return nil, []error{fmt.Errorf("invalid duration %q for %s: %w", s, key, err)}
+	}
+	return nil, nil
+}
+
+// resolveUserEmails maps every email to the UUID of the workspace member, keeping the UUIDs already in known so
+// state stays stable. The error lists every email which is not a workspace member.
+func resolveUserEmails(ctx context.Context, c *client.Client, emails []string, known map[string]string) (map[string]string, error) {
+	resolved := make(map[string]string, len(emails))
+	missing := []string{}
+	for _, email := range uniqueSorted(emails) {
+		if id, ok := known[email]; ok && id != "" {
+			resolved[email] = id
+			continue
+		}
+		user, err := c.GetWorkspaceUserByEmail(ctx, c.WorkspaceUUID, email)
+		if err != nil {
+			if errors.Is(err, client.ErrNotFound) {
+				missing = append(missing, fmt.Sprintf("%q is not a member of the workspace", email))
+				continue
+			}
+			return nil, fmt.Errorf("failed to look up user %q: %w", email, err)
+		}
+		resolved[email] = user.Uuid
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("cannot resolve user_emails:\n  %s", strings.Join(missing, "\n  "))
+	}
+	return resolved, nil
+}
+
+// CustomizeDiff step resolving user_emails into resolved_user_ids at plan time, so unknown addresses fail
+// before apply. Resolution is left to apply while user_emails is unknown.
+func resolveUserEmailsDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if d.Id() != "" && !d.HasChange("user_emails") {
+		return nil
+	}
+	if !d.NewValueKnown("user_emails") {
+		return d.SetNewComputed("resolved_user_ids")
+	}
+	c, ok := meta.(*client.Client)
+	if !ok || c == nil {
+		return nil
+	}
+	resolved, err := resolveUserEmails(ctx, c, fromSchemaSetToStrings(d.Get("user_emails").(*schema.Set)), stringMap(d.Get("resolved_user_ids").(map[string]interface{})))
+	if err != nil {
+		return err
+	}
+	return d.SetNew("resolved_user_ids", resolved)
+}
+
+// applyUserEmails resolves user_emails, records the mapping in resolved_user_ids and returns the user UUIDs.
+func applyUserEmails(ctx context.Context, c *client.Client, d *schema.ResourceData) ([]string, error) {
+	emails := fromSchemaSetToStrings(d.Get("user_emails").(*schema.Set))
+	resolved, err := resolveUserEmails(ctx, c, emails, stringMap(d.Get("resolved_user_ids").(map[string]interface{})))
+	if err != nil {
+		return nil, err
+	}
+	if err := d.Set("resolved_user_ids", resolved); err != nil {
+		return nil, fmt.Errorf("failed to set resolved_user_ids: %w", err)
+	}
+	return resolvedIDs(emails, resolved), nil
+}
+
+// ids of the names which have been resolved
+func resolvedIDs(names []string, resolved map[string]string) []string {
+	out := []string{}
+	for _, n := range names {
+		if id, ok := resolved[n]; ok {
+			out = append(out, id)
+		}
+	}
+	return uniqueSorted(out)
+}
+
+// readResolved keeps the names in namesKey whose id is still bound remotely, so an out-of-band removal shows up as
+// drift, and returns the ids that came only from namesKey and not from idsKey.
+func readResolved(d *schema.ResourceData, namesKey, resolvedKey, idsKey string, remote []string) ([]string, error) {
+	names := fromSchemaSetToStrings(d.Get(namesKey).(*schema.Set))
+	resolved := stringMap(d.Get(resolvedKey).(map[string]interface{}))
+	bound, kept := boundNames(names, resolved, remote)
+	if err := d.Set(namesKey, bound); err != nil {
+		return nil, fmt.Errorf("failed to set %s: %w", namesKey, err)
+	}
+	if err := d.Set(resolvedKey, kept); err != nil {
+		return nil, fmt.Errorf("failed to set %s: %w", resolvedKey, err)
+	}
+	return diff(toSet(resolvedIDs(bound, kept)), toSet(fromSchemaSetToStrings(d.Get(idsKey).(*schema.Set)))), nil
+}
+
+// names whose resolved id is in remote, with their part of the resolved mapping
+func boundNames(names []string, resolved map[string]string, remote []string) ([]string, map[string]string) {
+	remoteSet := toSet(remote)
+	bound := []string{}
+	kept := map[string]string{}
+	for _, n := range uniqueSorted(names) {
+		id, ok := resolved[n]
+		if !ok {
+			continue
+		}
+		if _, ok := remoteSet[id]; ok {
+			bound = append(bound, n)
+			kept[n] = id
+		}
+	}
+	return bound, kept
+}
+
+// IAM object with a name, used to resolve names to UUIDs
+type namedObject struct {
+	Name string
+	Uuid string
+}
+
+// resolveNames maps every name to the UUID of the only object with that name, keeping the UUIDs already in known
+// so state stays stable. The error lists every name which matches no object or more than one.
+func resolveNames(kind string, names []string, objects []namedObject, known map[string]string) (map[string]string, error) {
+	resolved := make(map[string]string, len(names))
+	problems := []string{}
+	for _, name := range uniqueSorted(names) {
+		if id, ok := known[name]; ok && id != "" {
+			resolved[name] = id
+			continue
+		}
+		matches := []namedObject{}
+		for _, o := range objects {
+			if o.Name == name {
+				matches = append(matches, o)
+			}
+		}
+		switch len(matches) {
+		case 0:
+			problems = append(problems, fmt.Sprintf("no %s named %q", kind, name))
+		case 1:
+			resolved[name] = matches[0].Uuid
+		default:
+			problems = append(problems, fmt.Sprintf("%d %ss are named %q, set scope to %q or %q", len(matches), kind, name, scopeWorkspace, scopeGlobal))
+		}
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("cannot resolve %s names:\n  %s", kind, strings.Join(problems, "\n  "))
+	}
+	return resolved, nil
+}
+
+// nameLookup describes how names of one kind of IAM object are resolved to UUIDs
+type nameLookup struct {
+	kind string
+	// attribute holding the scope, empty when the kind has no global objects
+	scopeKey string
+	list     func(ctx context.Context, c *client.Client, scope string) ([]namedObject, error)
+}
+
+var (
+	roleLookup  = nameLookup{kind: "role", scopeKey: "scope", list: listNamedRoles}
+	ruleLookup  = nameLookup{kind: "rule", scopeKey: "scope", list: listNamedRules}
+	groupLookup = nameLookup{kind: "group", list: listNamedGroups}
+)
+
+func (l nameLookup) keys(namesKey string) []string {
+	if l.scopeKey == "" {
+		return []string{namesKey}
+	}
+	return []string{namesKey, l.scopeKey}
+}
+
+func (l nameLookup) scope(get func(string) interface{}) string {
+	if l.scopeKey == "" {
+		return ""
+	}
+	return get(l.scopeKey).(string)
+}
+
+// resolve maps names to UUIDs, listing the objects only when some name is not in known yet
+func (l nameLookup) resolve(ctx context.Context, c *client.Client, scope string, names []string, known map[string]string) (map[string]string, error) {
+	var objects []namedObject
+	for _, n := range names {
+		if _, ok := known[n]; !ok {
+			var err error
+			if objects, err = l.list(ctx, c, scope); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+	return resolveNames(l.kind, names, objects, known)
+}
+
+// CustomizeDiff step resolving the names in namesKey into the resolvedKey map at plan time
+func (l nameLookup) resolveSetDiff(namesKey, resolvedKey string) schema.CustomizeDiffFunc {
+	return func(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+		keys := l.keys(namesKey)
+		if d.Id() != "" && !d.HasChanges(keys...) {
+			return nil
+		}
+		for _, k := range keys {
+			if !d.NewValueKnown(k) {
+				return d.SetNewComputed(resolvedKey)
+			}
+		}
+		c, ok := meta.(*client.Client)
+		if !ok || c == nil {
+			return nil
+		}
+		known := stringMap(d.Get(resolvedKey).(map[string]interface{}))
+		if l.scopeKey != "" && d.HasChange(l.scopeKey) {
+			known = nil
+		}
+		resolved, err := l.resolve(ctx, c, l.scope(d.Get), fromSchemaSetToStrings(d.Get(namesKey).(*schema.Set)), known)
+		if err != nil {
+			return err
+		}
+		return d.SetNew(resolvedKey, resolved)
+	}
+}
+
+// resolveSet resolves the names in namesKey, records the mapping in resolvedKey and returns the UUIDs.
+func (l nameLookup) resolveSet(ctx context.Context, c *client.Client, d *schema.ResourceData, namesKey, resolvedKey string) ([]string, error) {
+	names := fromSchemaSetToStrings(d.Get(namesKey).(*schema.Set))
+	resolved, err := l.resolve(ctx, c, l.scope(d.Get), names, stringMap(d.Get(resolvedKey).(map[string]interface{})))
+	if err != nil {
+		return nil, err
+	}
+	if err := d.Set(resolvedKey, resolved); err != nil {
+		return nil, fmt.Errorf("failed to set %s: %w", resolvedKey, err)
+	}
+	return resolvedIDs(names, resolved), nil
+}
+
+// CustomizeDiff step resolving the name in nameKey into idKey at plan time
+func (l nameLookup) resolveOneDiff(nameKey, idKey string) schema.CustomizeDiffFunc {
+	return func(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+		keys := l.keys(nameKey)
+		if d.Id() != "" && !d.HasChanges(keys...) {
+			return nil
+		}
+		for _, k := range keys {
+			if !d.NewValueKnown(k) {
+				return d.SetNewComputed(idKey)
+			}
+		}
+		name := d.Get(nameKey).(string)
+		c, ok := meta.(*client.Client)
+		if name == "" || !ok || c == nil {
+			return nil
+		}
+		resolved, err := l.resolve(ctx, c, l.scope(d.Get), []string{name}, nil)
+		if err != nil {
+			return err
+		}
+		return d.SetNew(idKey, resolved[name])
+	}
+}
+
+// resolveOne sets idKey from the name in nameKey when the plan could not resolve it.
+func (l nameLookup) resolveOne(ctx context.Context, c *client.Client, d *schema.ResourceData, nameKey, idKey string) error {
+	name := d.Get(nameKey).(string)
+	if name == "" || d.Get(idKey).(string) != "" {
+		return nil
+	}
+	resolved, err := l.resolve(ctx, c, l.scope(d.Get), []string{name}, nil)
+	if err != nil {
+		return err
+	}
+	if err := d.Set(idKey, resolved[name]); err != nil {
+		return fmt.Errorf("failed to set %s: %w", idKey, err)
+	}
+	return nil
+}
+
+// schema of the scope argument narrowing name resolution to workspace or global objects
+func nameScopeSchema(namesKeys ...string) *schema.Schema {
+	return &schema.Schema{
+		Type:         schema.TypeString,
+		Optional:     true,
+		ValidateFunc: validation.StringInSlice([]string{scopeWorkspace, scopeGlobal}, false),
+		Description: "Where `" + strings.Join(namesKeys, "`, `") + "` are looked up: `workspace` or `global`. " +
+			"By default both are searched and a name found in both is an error.",
+	}
+}
+
+func listNamedRoles(ctx context.Context, c *client.Client, scope string) ([]namedObject, error) {
+	out := []namedObject{}
+	for _, ws := range scopeWorkspaces(c, scope) {
+		roles, err := c.GetWorkspaceRoles(ctx, ws)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list roles: %w", err)
+		}
+		for _, r := range roles {
+			out = append(out, namedObject{Name: r.Name, Uuid: r.Uuid})
+		}
+	}
+	return out, nil
+}
+
+func listNamedRules(ctx context.Context, c *client.Client, scope string) ([]namedObject, error) {
+	out := []namedObject{}
+	for _, ws := range scopeWorkspaces(c, scope) {
+		rules, err := c.GetWorkspaceRules(ctx, ws)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list rules: %w", err)
+		}
+		for _, r := range rules {
+			out = append(out, namedObject{Name: r.Name, Uuid: r.Uuid})
+		}
+	}
+	return out, nil
+}
+
+// groups only exist in the workspace
+func listNamedGroups(ctx context.Context, c *client.Client, scope string) ([]namedObject, error) {
+	groups, err := c.GetWorkspaceGroups(ctx, c.WorkspaceUUID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list groups: %w", err)
+	}
+	out := make([]namedObject, 0, len(groups))
+	for _, g := range groups {
+		out = append(out, namedObject{Name: g.Name, Uuid: g.Uuid})
+	}
+	return out, nil
+}
+
+// workspaces searched for the scope, the provider workspace and the global one when scope is empty
+func scopeWorkspaces(c *client.Client, scope string) []string {
+	switch scope {
+	case scopeWorkspace:
+		return []string{c.Workspace}
+	case scopeGlobal:
+		return []string{GlobalWorkspaceUUID.String()}
+	}
+	return []string{c.Workspace, GlobalWorkspaceUUID.String()}
+}
+
+// pickNamed returns the index of the only object with the id, or with the name when id is empty. The error
+// explains a lookup which matched nothing or several objects; scoped adds a hint to narrow the scope.
+func pickNamed(kind, id, name string, objects []namedObject, scoped bool) (int, error) {
+	matches := []int{}
+	for i, o := range objects {
+		if (id != "" && o.Uuid == id) || (id == "" && o.Name == name) {
+			matches = append(matches, i)
+		}
+	}
+	switch {
+	case len(matches) == 1:
+		return matches[0], nil
+	case len(matches) == 0 && id != "":
+		return 0, fmt.Errorf("no %s with id %q", kind, id)
+	case len(matches) == 0:
+		similar := []string{}
+		for _, o := range objects {
+			if strings.Contains(strings.ToLower(o.Name), strings.ToLower(name)) {
+				similar = append(similar, fmt.Sprintf("%q", o.Name))
+			}
+		}
+		if len(similar) == 0 {
+			return 0, fmt.Errorf("no %s named %q", kind, name)
+		}
+		return 0, fmt.Errorf("no %s named %q, similar names: %s", kind, name, strings.Join(uniqueSorted(similar), ", "))
+	}
+	ids := make([]string, 0, len(matches))
+	for _, i := range matches {
+		ids = append(ids, objects[i].Uuid)
+	}
+	hint := "look it up by id"
+	if scoped {
+		hint = fmt.Sprintf("look it up by id or set scope to %q or %q", scopeWorkspace, scopeGlobal)
+	}
+	return 0, fmt.Errorf("%d %ss are named %q (%s), %s", len(matches), kind, name, strings.Join(ids, ", "), hint)
+}
+
+// schemas of the name_regex and name_prefix filters of the list data sources
+func nameFilterSchemas(kind string) map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"name_regex": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			ValidateFunc: validation.StringIsValidRegExp,
+			Description:  "Only return " + kind + " whose name matches this regular expression.",
+		},
+		"name_prefix": {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: "Only return " + kind + " whose name starts with this prefix.",
+		},
+	}
+}
+
+// nameFilter holds the name_regex and name_prefix filters of a list data source
+type nameFilter struct {
+	regex  *regexp.Regexp
+	prefix string
+}
+
+func newNameFilter(d *schema.ResourceData) (nameFilter, error) {
+	f := nameFilter{}
+	if raw, ok := d.GetOk("name_prefix"); ok {
+		f.prefix = raw.(string)
+	}
+	if raw, ok := d.GetOk("name_regex"); ok {
+		re, err := regexp.Compile(raw.(string))
+		if err != nil {
+			return f, fmt.Errorf("invalid name_regex: %w", err)
+		}
+		f.regex = re
+	}
+	return f, nil
+}
+
+func (f nameFilter) match(name string) bool {
+	if !strings.HasPrefix(name, f.prefix) {
+		return false
+	}
+	return f.regex == nil || f.regex.MatchString(name)
+}
+
+// merges the filter schemas into a data source schema
+func withFilters(base map[string]*schema.Schema, filters ...map[string]*schema.Schema) map[string]*schema.Schema {
+	for _, f := range filters {
+		for k, v := range f {
+			base[k] = v
+		}
+	}
+	return base
+}
+
+// parseLongDuration parses a Go duration which may start with a number of days, such as 90d or 1d12h
+func parseLongDuration(s string) (time.Duration, error) {
+	days := 0
+	if s == "" {
+		return 0, fmt.Errorf("empty duration")
+	}
+	if i := strings.Index(s, "d"); i > 0 {
+		n, err := strconv.Atoi(s[:i])
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		days, s = n, s[i+1:]
+	}
+	rest := time.Duration(0)
+	if s != "" {
+		var err error
+		if rest, err = time.ParseDuration(s); err != nil {
+			return 0, err
+		}
+	}
+	if days < 0 || rest < 0 {
+		return 0, fmt.Errorf("duration must not be negative")
+	}
+	return time.Duration(days)*24*time.Hour + rest, nil
+}
+
+func validateLongDuration(v interface{}, key string) ([]string, []error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, []error{fmt.Errorf("expected %s to be a string", key)}
+	}
+	if _, err := parseLongDuration(s); err != nil {
+		return nil, []error{fmt.Errorf("invalid duration %q for %s, expected a duration such as 90d or 36h: %w", s, key, err)}
+	}
+	return nil, nil
+}
+
+// parseExpiry parses a token expiry given in RFC3339 or as a date, which means midnight UTC
+func parseExpiry(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected an RFC3339 timestamp such as 2025-09-30T00:00:00Z or a date such as 2025-09-30, got %q", s)
+	}
+	return t, nil
+}
+
+func validateExpiry(v interface{}, key string) ([]string, []error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, []error{fmt.Errorf("expected %s to be a string", key)}
+	}
+	if _, err := parseExpiry(s); err != nil {
+		return nil, []error{fmt.Errorf("invalid %s: %w", key, err)}
+	}
+	return nil, nil
+}
+
+// suppresses the diff between two spellings of the same expiry, such as a date and the timestamp the API returns
+func suppressEquivalentExpiry(k, old, new string, d *schema.ResourceData) bool {
+	o, err := parseExpiry(old)
+	if err != nil {
+		return false
+	}
+	n, err := parseExpiry(new)
+	if err != nil {
+		return false
+	}
+	return o.Equal(n)
+}
+
+// configuredExpiry returns the expiry requested by expires_in, or by the expiry argument when it is set in the
+// configuration, and nil for a token without expiry
+func configuredExpiry(d *schema.ResourceData, expiryKey string, now time.Time) (*time.Time, error) {
+	if raw, ok := d.GetOk("expires_in"); ok {
+		in, err := parseLongDuration(raw.(string))
+		if err != nil {
+			return nil, fmt.Errorf("invalid expires_in: %w", err)
+		}
+		t := now.Add(in).UTC().Truncate(time.Second)
+		return &t, nil
+	}
+	if d.GetRawConfig().GetAttr(expiryKey).IsNull() {
+		return nil, nil
+	}
+	t, err := parseExpiry(d.Get(expiryKey).(string))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", expiryKey, err)
+	}
+	return &t, nil
+}
+
+// reports whether a token expiring at expiresAt has entered its renewal window, tokens without expiry never do
+func renewalDue(expiresAt time.Time, renewBefore time.Duration, now time.Time) bool {
+	return !expiresAt.IsZero() && !now.Before(expiresAt.Add(-renewBefore))
+}
+
+// CustomizeDiff step planning the replacement of a token once it is within renew_before of its expiry, by
+// marking the expiry attribute unknown and forcing a new resource on it.
+func renewTokenDiff(expiryKey string) schema.CustomizeDiffFunc {
+	return func(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+		raw, ok := d.GetOk("renew_before")
+		if d.Id() == "" || !ok {
+			return nil
+		}
+		renewBefore, err := parseLongDuration(raw.(string))
+		if err != nil {
+			return fmt.Errorf("invalid renew_before: %w", err)
+		}
+		expiresAt, err := parseExpiry(d.Get(expiryKey).(string))
+		if err != nil || !renewalDue(expiresAt, renewBefore, time.Now()) {
+			return nil
+		}
+		tflog.Info(ctx, "Token is within renew_before of its expiry, planning a replacement", map[string]interface{}{"expires_at": expiresAt})
+		if err := d.SetNewComputed(expiryKey); err != nil {
+			return err
+		}
+		return d.ForceNew(expiryKey)
+	}
+}
@@ -1,9 +1,19 @@
 package provider
 
 import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	uuid "github.com/satori/go.uuid"
+	iam "github.com/sotoon/sotoon-sdk-go/sdk/core/iam_v1"
+	"github.com/sotoon/terraform-provider-sotoon/internal/client"
 	"reflect"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestUnituniqueSortedEmptyString(t *testing.T) {
@@ -57,4 +67,1306 @@ func TestUnitfromSchemaSetToStrings(t *testing.T) {
 			t.Fatalf("fromSchemaSetToStrings expect contains %q but not(%q)", v, got)
 		} 
 	} 
-}
\ No newline at end of file
+}
+
+func TestUnitruleChanges(t *testing.T) {
+	toAdd, toRemove := ruleChanges([]string{"b", "c", "c"}, []string{"a", "b"})
+	if !reflect.DeepEqual(toAdd, []string{"c"}) || !reflect.DeepEqual(toRemove, []string{"a"}) {
+		t.Fatalf("ruleChanges expect ([c], [a]) but returned (%q, %q)", toAdd, toRemove)
+	}
+
+	toAdd, toRemove = ruleChanges(nil, []string{"b", "a"})
+	if len(toAdd) != 0 || !reflect.DeepEqual(toRemove, []string{"a", "b"}) {
+		t.Fatalf("ruleChanges for an empty exclusive set expect every rule detached but returned (%q, %q)", toAdd, toRemove)
+	}
+
+	toAdd, toRemove = ruleChanges([]string{"a"}, []string{"a"})
+	if len(toAdd) != 0 || len(toRemove) != 0 {
+		t.Fatalf("ruleChanges for matching rules expect no changes but returned (%q, %q)", toAdd, toRemove)
+	}
+}
+
+func TestUnitmemberDeltaInPlace(t *testing.T) {
+	desired := []string{"a", "c"}
+	remote := []string{"a", "b", "x"}
+	managed := []string{"a", "b"}
+
+	toAdd, toRemove := memberDelta(desired, remote, managed, false)
+	if !reflect.DeepEqual(toAdd, []string{"c"}) || !reflect.DeepEqual(toRemove, []string{"b"}) {
+		t.Fatalf("memberDelta expect ([c], [b]) but returned (%q, %q)", toAdd, toRemove)
+	}
+
+	toAdd, toRemove = memberDelta(desired, remote, managed, true)
+	if !reflect.DeepEqual(toAdd, []string{"c"}) || !reflect.DeepEqual(toRemove, []string{"b", "x"}) {
+		t.Fatalf("memberDelta in exclusive mode expect ([c], [b x]) but returned (%q, %q)", toAdd, toRemove)
+	}
+}
+
+func TestUnitmemberDeltaUnchanged(t *testing.T) {
+	toAdd, toRemove := memberDelta([]string{"a", "b"}, []string{"a", "b", "x"}, []string{"a", "b"}, false)
+	if len(toAdd) != 0 || len(toRemove) != 0 {
+		t.Fatalf("memberDelta for an unchanged binding expect no changes but returned (%q, %q)", toAdd, toRemove)
+	}
+}
+
+func TestUniteffectiveIDsIntersectsWhenNotExclusive(t *testing.T) {
+	local := []string{"a", "b", "c"}
+	remote := []string{"c", "b", "d"}
+	expect := []string{"b", "c"}
+
+	if got := effectiveIDs(local, remote, false); !reflect.DeepEqual(got, expect) {
+		t.Fatalf("effectiveIDs expect return %q but returned %q", expect, got)
+	}
+}
+
+func TestUniteffectiveIDsReturnsRemoteWhenExclusive(t *testing.T) {
+	local := []string{"a", "b"}
+	remote := []string{"c", "b", "d"}
+	expect := []string{"b", "c", "d"}
+
+	if got := effectiveIDs(local, remote, true); !reflect.DeepEqual(got, expect) {
+		t.Fatalf("effectiveIDs expect return %q but returned %q", expect, got)
+	}
+}
+
+func TestUnitidsToRemoveOnlyOldWhenNotExclusive(t *testing.T) {
+	old := []string{"a", "b"}
+	desired := []string{"b", "c"}
+	remote := []string{"a", "b", "x"}
+	expect := []string{"a"}
+
+	if got := idsToRemove(old, desired, remote, false); !reflect.DeepEqual(got, expect) {
+		t.Fatalf("idsToRemove expect return %q but returned %q", expect, got)
+	}
+}
+
+func TestUnitidsToRemoveIncludesUnmanagedWhenExclusive(t *testing.T) {
+	old := []string{"a", "b"}
+	desired := []string{"b", "c"}
+	remote := []string{"a", "b", "x"}
+	expect := []string{"a", "x"}
+
+	if got := idsToRemove(old, desired, remote, true); !reflect.DeepEqual(got, expect) {
+		t.Fatalf("idsToRemove expect return %q but returned %q", expect, got)
+	}
+}
+
+func TestUnitbindingItemsRejectsSeveralBindings(t *testing.T) {
+	in := []map[string]string{{"bucket": "a"}, {"bucket": "b"}}
+
+	if got, err := bindingItems(in); err == nil {
+		t.Fatalf("bindingItems for two bindings expect error but returned %v", got)
+	}
+}
+
+func TestUnitbindingItemsSingleBinding(t *testing.T) {
+	in := []map[string]string{{"zone": "a", "project": "p"}, {}, {"project": "p", "zone": "a"}}
+	expect := map[string]string{"zone": "a", "project": "p"}
+
+	if got, err := bindingItems(in); err != nil || !reflect.DeepEqual(got, expect) {
+		t.Fatalf("bindingItems expect return %v but returned (%v, %v)", expect, got, err)
+	}
+}
+
+func TestUnitbindingItemsEmpty(t *testing.T) {
+	if got, err := bindingItems(nil); err != nil || len(got) != 0 {
+		t.Fatalf("bindingItems for nil input expect to return empty map but returned (%v, %v)", got, err)
+	}
+}
+
+func TestUnitmanagedAfterAddsAndRemoves(t *testing.T) {
+	managed := []string{"a", "b"}
+	added := []string{"c"}
+	removed := []string{"a", "x"}
+	expect := []string{"b", "c"}
+
+	if got := managedAfter(managed, added, removed); !reflect.DeepEqual(got, expect) {
+		t.Fatalf("managedAfter expect return %q but returned %q", expect, got)
+	}
+}
+
+func TestUnititemsMatchIgnoresEmptyEntries(t *testing.T) {
+	desired := map[string]string{"namespace": "prod"}
+	remote := []map[string]string{{}, {"namespace": "prod"}}
+
+	if !itemsMatch(desired, remote) {
+		t.Fatalf("itemsMatch expect %v to match %v", remote, desired)
+	}
+	if !itemsMatch(map[string]string{}, []map[string]string{{}}) {
+		t.Fatalf("itemsMatch expect empty entries to match no items")
+	}
+}
+
+func TestUnititemsMatchDetectsChangedValue(t *testing.T) {
+	desired := map[string]string{"namespace": "prod"}
+	remote := []map[string]string{{"namespace": "staging"}}
+
+	if itemsMatch(desired, remote) {
+		t.Fatalf("itemsMatch expect %v not to match %v", remote, desired)
+	}
+}
+
+func TestUnitdriftedItemIDs(t *testing.T) {
+	desired := map[string]string{"namespace": "prod"}
+	remote := map[string][]map[string]string{
+		"a": {{"namespace": "prod"}},
+		"b": {{"namespace": "staging"}},
+		"c": nil,
+	}
+	expect := []string{"b", "c"}
+
+	if got := driftedItemIDs([]string{"c", "b", "a"}, remote, desired); !reflect.DeepEqual(got, expect) {
+		t.Fatalf("driftedItemIDs expect return %q but returned %q", expect, got)
+	}
+}
+
+func TestUnitbindingItemsListNilWithoutItems(t *testing.T) {
+	bindings := map[string]map[string]string{"a": {}, "b": nil}
+
+	if got := bindingItemsList([]string{"a", "b"}, bindings); got != nil {
+		t.Fatalf("bindingItemsList expect nil when no member has items but returned %v", got)
+	}
+}
+
+func TestUnitbindingItemsListKeepsOrder(t *testing.T) {
+	bindings := map[string]map[string]string{"a": {"namespace": "dev"}, "b": {"namespace": "prod"}}
+	expect := []map[string]string{{"namespace": "prod"}, {"namespace": "dev"}}
+
+	if got := bindingItemsList([]string{"b", "a"}, bindings); !reflect.DeepEqual(got, expect) {
+		t.Fatalf("bindingItemsList expect return %v but returned %v", expect, got)
+	}
+}
+
+func TestUnitflattenBindingsKeepsMatchingAndReportsDrift(t *testing.T) {
+	desired := map[string]map[string]string{"a": {"namespace": "dev"}, "b": {"namespace": "prod"}}
+	remote := map[string][]map[string]string{
+		"a": {{"namespace": "dev"}},
+		"b": {{"namespace": "staging"}},
+	}
+	expect := []interface{}{
+		map[string]interface{}{"id": "a", "items": map[string]string{"namespace": "dev"}},
+		map[string]interface{}{"id": "b", "items": map[string]string{"namespace": "staging"}},
+	}
+
+	if got, err := flattenBindings([]string{"b", "a"}, []string{"a", "b"}, desired, remote); err != nil || !reflect.DeepEqual(got, expect) {
+		t.Fatalf("flattenBindings expect return %v but returned (%v, %v)", expect, got, err)
+	}
+}
+
+func TestUnitflattenBindingsRejectsSeveralBindings(t *testing.T) {
+	desired := map[string]map[string]string{"a": {"bucket": "a"}}
+	remote := map[string][]map[string]string{"a": {{"bucket": "a"}, {"bucket": "b"}}}
+
+	if got, err := flattenBindings([]string{"a"}, []string{"a"}, desired, remote); err == nil {
+		t.Fatalf("flattenBindings for a member bound twice expect error but returned %v", got)
+	}
+}
+
+func TestUnitvalidateRoleItemsSkipsUndescribedRoles(t *testing.T) {
+	if err := validateRoleItems("r", nil, map[string]string{"anything": "x"}); err != nil {
+		t.Fatalf("validateRoleItems expect no error for undescribed role but returned %s", err)
+	}
+}
+
+func TestUnitvalidateRoleItemsRejectsUnknownKey(t *testing.T) {
+	possible := map[string][]string{"bucket": {}}
+
+	if err := validateRoleItems("r", &possible, map[string]string{"bukcet": "logs"}); err == nil {
+		t.Fatalf("validateRoleItems expect error for unknown item key")
+	}
+	if err := validateRoleItems("r", &possible, map[string]string{"bucket": "logs"}); err != nil {
+		t.Fatalf("validateRoleItems expect no error for accepted key but returned %s", err)
+	}
+}
+
+func TestUnitvalidateRoleItemsRejectsDisallowedValue(t *testing.T) {
+	possible := map[string][]string{"zone": {"thr1", "thr2"}}
+
+	if err := validateRoleItems("r", &possible, map[string]string{"zone": "thr3"}); err == nil {
+		t.Fatalf("validateRoleItems expect error for value outside allowed values")
+	}
+	if err := validateRoleItems("r", &possible, map[string]string{"zone": "thr2"}); err != nil {
+		t.Fatalf("validateRoleItems expect no error for allowed value but returned %s", err)
+	}
+}
+
+func TestUnitvalidateRoleItemsRejectsItemsForRoleWithoutItems(t *testing.T) {
+	possible := map[string][]string{}
+
+	if err := validateRoleItems("r", &possible, map[string]string{"zone": "thr1"}); err == nil {
+		t.Fatalf("validateRoleItems expect error for role which accepts no items")
+	}
+}
+
+func TestUnituserDetachOrder(t *testing.T) {
+	detailed := &iam.IamUserWorkspaceDetailedUser{
+		Groups: []iam.IamGroupWithMinimalRole{{Uuid: "g2"}, {Uuid: "g1"}},
+		Roles: []iam.IamRoleMinimal{
+			{Uuid: "22222222-2222-2222-2222-222222222222"},
+			{Uuid: "11111111-1111-1111-1111-111111111111"},
+		},
+	}
+	groupIDs, roleIDs, err := userDetachOrder(detailed)
+	if err != nil {
+		t.Fatalf("userDetachOrder returned error %s", err)
+	}
+	if !reflect.DeepEqual(groupIDs, []string{"g1", "g2"}) {
+		t.Fatalf("userDetachOrder expect groups [g1 g2] but returned %v", groupIDs)
+	}
+	if len(roleIDs) != 2 || roleIDs[0].String() != "11111111-1111-1111-1111-111111111111" || roleIDs[1].String() != "22222222-2222-2222-2222-222222222222" {
+		t.Fatalf("userDetachOrder expect sorted role ids but returned %v", roleIDs)
+	}
+}
+
+func TestUnituserDetachOrderInvalidRole(t *testing.T) {
+	detailed := &iam.IamUserWorkspaceDetailedUser{Roles: []iam.IamRoleMinimal{{Uuid: "not-a-uuid"}}}
+	if _, _, err := userDetachOrder(detailed); err == nil {
+		t.Fatalf("userDetachOrder expect error for invalid role uuid")
+	}
+}
+
+func TestUnitinvitationExpiry(t *testing.T) {
+	now := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	expiresAt, expired, err := invitationExpiry("2024-01-05T00:00:00Z", 168*time.Hour, now)
+	if err != nil {
+		t.Fatalf("invitationExpiry returned error %s", err)
+	}
+	if expect := time.Date(2024, 1, 12, 0, 0, 0, 0, time.UTC); !expiresAt.Equal(expect) || expired {
+		t.Fatalf("invitationExpiry expect (%s, false) but returned (%s, %t)", expect, expiresAt, expired)
+	}
+
+	if _, expired, _ := invitationExpiry("2024-01-01T00:00:00Z", 72*time.Hour, now); !expired {
+		t.Fatalf("invitationExpiry expect expired invitation")
+	}
+	if _, _, err := invitationExpiry("yesterday", time.Hour, now); err == nil {
+		t.Fatalf("invitationExpiry expect error for invalid time")
+	}
+}
+
+func TestUnitvalidateDuration(t *testing.T) {
+	if _, errs := validateDuration("30m", "d"); len(errs) != 0 {
+		t.Fatalf("validateDuration expect no error but returned %v", errs)
+	}
+	if _, errs := validateDuration("7 days", "d"); len(errs) == 0 {
+		t.Fatalf("validateDuration expect error for invalid duration")
+	}
+}
+
+func TestUnitrefreshInvitations(t *testing.T) {
+	now := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	tracked := []invitationState{
+		{Email: "b@example.com", Status: userStatusInvited, InvitedAt: "2024-01-09T00:00:00Z"},
+		{Email: "A@example.com", Status: userStatusInvited, InvitedAt: "2024-01-09T00:00:00Z"},
+		{Email: "c@example.com", Status: userStatusInvited, InvitedAt: "2024-01-01T00:00:00Z"},
+		{Email: "d@example.com", Status: invitationStatusFailed, Error: "invitation rejected"},
+	}
+	members := map[string]struct{}{"a@example.com": {}}
+
+	got := refreshInvitations(tracked, members, 72*time.Hour, now)
+	expect := []invitationState{
+		{Email: "A@example.com", Status: userStatusActive, InvitedAt: "2024-01-09T00:00:00Z"},
+		{Email: "b@example.com", Status: userStatusInvited, InvitedAt: "2024-01-09T00:00:00Z", ExpiresAt: "2024-01-12T00:00:00Z"},
+		{Email: "c@example.com", Status: userStatusExpired, InvitedAt: "2024-01-01T00:00:00Z", ExpiresAt: "2024-01-04T00:00:00Z"},
+		{Email: "d@example.com", Status: invitationStatusFailed, Error: "invitation rejected"},
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Fatalf("refreshInvitations expect return %v but returned %v", expect, got)
+	}
+}
+
+func TestUnitemailsToInvite(t *testing.T) {
+	tracked := []invitationState{
+		{Email: "a@example.com", Status: userStatusInvited},
+		{Email: "b@example.com", Status: userStatusExpired},
+		{Email: "c@example.com", Status: invitationStatusFailed},
+	}
+
+	got := emailsToInvite([]string{"d@example.com", "c@example.com", "b@example.com", "a@example.com"}, tracked)
+	if expect := []string{"b@example.com", "c@example.com", "d@example.com"}; !reflect.DeepEqual(got, expect) {
+		t.Fatalf("emailsToInvite expect return %v but returned %v", expect, got)
+	}
+}
+
+func TestUnitanyNeedsReinvite(t *testing.T) {
+	cases := []struct {
+		status string
+		expect bool
+	}{
+		{userStatusInvited, false},
+		{userStatusActive, false},
+		{userStatusExpired, true},
+		{invitationStatusFailed, true},
+	}
+	for _, tc := range cases {
+		tracked := []invitationState{{Email: "a@example.com", Status: userStatusInvited}, {Email: "b@example.com", Status: tc.status}}
+		if got := anyNeedsReinvite(tracked); got != tc.expect {
+			t.Errorf("anyNeedsReinvite with status %q expect return %v but returned %v", tc.status, tc.expect, got)
+		}
+	}
+}
+
+func TestUnitisProviderUser(t *testing.T) {
+	const self = "8f7e9a2c-1b3d-4e5f-a6b7-c8d9e0f1a2b3"
+
+	if !isProviderUser("8F7E9A2C-1B3D-4E5F-A6B7-C8D9E0F1A2B3", self) {
+		t.Fatalf("isProviderUser expect true for the provider's own user")
+	}
+	if isProviderUser("", self) || isProviderUser("0a1b2c3d-0000-0000-0000-000000000000", self) {
+		t.Fatalf("isProviderUser expect false for other or unknown users")
+	}
+}
+
+func TestUnitresolvedIDs(t *testing.T) {
+	resolved := map[string]string{"a@example.com": "u2", "b@example.com": "u1", "c@example.com": "u1"}
+
+	got := resolvedIDs([]string{"a@example.com", "b@example.com", "c@example.com", "unresolved@example.com"}, resolved)
+	if expect := []string{"u1", "u2"}; !reflect.DeepEqual(got, expect) {
+		t.Fatalf("resolvedIDs expect return %v but returned %v", expect, got)
+	}
+}
+
+func TestUnitboundNames(t *testing.T) {
+	resolved := map[string]string{"a@example.com": "u1", "b@example.com": "u2"}
+
+	bound, kept := boundNames([]string{"b@example.com", "a@example.com", "c@example.com"}, resolved, []string{"u1", "u3"})
+	if expect := []string{"a@example.com"}; !reflect.DeepEqual(bound, expect) {
+		t.Fatalf("boundNames expect return %v but returned %v", expect, bound)
+	}
+	if expect := map[string]string{"a@example.com": "u1"}; !reflect.DeepEqual(kept, expect) {
+		t.Fatalf("boundNames expect keep %v but kept %v", expect, kept)
+	}
+}
+
+func TestUnitresolveNames(t *testing.T) {
+	objects := []namedObject{
+		{Name: "viewer", Uuid: "r1"},
+		{Name: "editor", Uuid: "r2"},
+		{Name: "admin", Uuid: "r3"},
+		{Name: "admin", Uuid: "g3"},
+	}
+
+	got, err := resolveNames("role", []string{"viewer", "editor"}, objects, map[string]string{"editor": "old"})
+	if err != nil {
+		t.Fatalf("resolveNames returned error %s", err)
+	}
+	if expect := map[string]string{"viewer": "r1", "editor": "old"}; !reflect.DeepEqual(got, expect) {
+		t.Fatalf("resolveNames expect return %v but returned %v", expect, got)
+	}
+}
+
+func TestUnitresolveNamesReportsEveryProblem(t *testing.T) {
+	objects := []namedObject{{Name: "admin", Uuid: "r3"}, {Name: "admin", Uuid: "g3"}}
+
+	_, err := resolveNames("role", []string{"admin", "missing"}, objects, nil)
+	if err == nil {
+		t.Fatalf("resolveNames expect error for ambiguous and unknown names")
+	}
+	for _, want := range []string{`2 roles are named "admin"`, `no role named "missing"`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("resolveNames error expect to contain %q but was %q", want, err)
+		}
+	}
+}
+
+func TestUnitpickNamed(t *testing.T) {
+	objects := []namedObject{{Name: "viewer", Uuid: "r1"}, {Name: "editor", Uuid: "r2"}}
+
+	if i, err := pickNamed("role", "", "editor", objects, true); err != nil || i != 1 {
+		t.Fatalf("pickNamed by name expect (1, nil) but returned (%d, %v)", i, err)
+	}
+	if i, err := pickNamed("role", "r1", "", objects, true); err != nil || i != 0 {
+		t.Fatalf("pickNamed by id expect (0, nil) but returned (%d, %v)", i, err)
+	}
+	if _, err := pickNamed("role", "r9", "", objects, true); err == nil {
+		t.Fatalf("pickNamed expect error for unknown id")
+	}
+}
+
+func TestUnitpickNamedExplainsFailures(t *testing.T) {
+	objects := []namedObject{{Name: "admin", Uuid: "r1"}, {Name: "admin", Uuid: "g1"}, {Name: "bucket-admin", Uuid: "r2"}}
+
+	_, err := pickNamed("role", "", "admin", objects, true)
+	if err == nil || !strings.Contains(err.Error(), "2 roles are named \"admin\" (r1, g1)") || !strings.Contains(err.Error(), "scope") {
+		t.Fatalf("pickNamed expect ambiguity error with ids and scope hint but returned %v", err)
+	}
+	_, err = pickNamed("role", "", "Admin", objects, false)
+	if err == nil || !strings.Contains(err.Error(), `similar names: "admin", "bucket-admin"`) {
+		t.Fatalf("pickNamed expect error with similar names but returned %v", err)
+	}
+}
+
+func TestUnitnewNameFilter(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, nameFilterSchemas("roles"), map[string]interface{}{
+		"name_regex":  "-(viewer|editor)$",
+		"name_prefix": "bucket",
+	})
+
+	f, err := newNameFilter(d)
+	if err != nil {
+		t.Fatalf("newNameFilter expect no error but returned %v", err)
+	}
+	for name, want := range map[string]bool{"bucket-viewer": true, "bucket-admin": false, "vm-viewer": false, "": false} {
+		if got := f.match(name); got != want {
+			t.Fatalf("nameFilter.match(%q) expect %v but returned %v", name, want, got)
+		}
+	}
+}
+
+func TestUnitnewNameFilterEmptyMatchesAll(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, nameFilterSchemas("roles"), map[string]interface{}{})
+
+	f, err := newNameFilter(d)
+	if err != nil || !f.match("anything") || !f.match("") {
+		t.Fatalf("newNameFilter without filters expect to match every name but returned %v", err)
+	}
+}
+
+func TestUnitemailInDomain(t *testing.T) {
+	cases := []struct {
+		email, domain string
+		want          bool
+	}{
+		{"alice@example.com", "example.com", true},
+		{"Alice@Example.COM", "@example.com", true},
+		{"alice@sub.example.com", "example.com", false},
+		{"alice@notexample.com", "example.com", false},
+	}
+	for _, tc := range cases {
+		if got := emailInDomain(tc.email, tc.domain); got != tc.want {
+			t.Fatalf("emailInDomain(%q, %q) expect %v but returned %v", tc.email, tc.domain, tc.want, got)
+		}
+	}
+}
+
+func TestUnitruleMatches(t *testing.T) {
+	actions := []string{"GET", "POST"}
+
+	if !ruleMatches(actions, "bucket/logs", "", "") {
+		t.Fatalf("ruleMatches without filters expect true")
+	}
+	if !ruleMatches(actions, "bucket/logs", "get", "bucket/") {
+		t.Fatalf("ruleMatches expect case-insensitive action match with object prefix")
+	}
+	if ruleMatches(actions, "bucket/logs", "DELETE", "") {
+		t.Fatalf("ruleMatches expect false for a missing action")
+	}
+	if ruleMatches(actions, "vm/logs", "GET", "bucket/") {
+		t.Fatalf("ruleMatches expect false for a different object prefix")
+	}
+}
+
+func TestUnitexpandPermissions(t *testing.T) {
+	grants := []roleGrant{
+		{RoleID: "r1", RoleName: "viewer", GroupID: "g1", GroupName: "devs"},
+		{RoleID: "r1", RoleName: "viewer"},
+		{RoleID: "r2", RoleName: "empty"},
+	}
+	roleRules := map[string][]iam.IamRule{
+		"r1": {{Uuid: "u1", Name: "bucket-read", Actions: []string{"LIST", "GET"}, Object: "bucket/*", Deny: false}},
+	}
+
+	got := expandPermissions(grants, roleRules)
+	sources := []string{}
+	for _, p := range got {
+		sources = append(sources, p.source()+" "+p.Action)
+	}
+	want := []string{
+		"group:devs/role:viewer/rule:bucket-read GET",
+		"group:devs/role:viewer/rule:bucket-read LIST",
+		"role:viewer/rule:bucket-read GET",
+		"role:viewer/rule:bucket-read LIST",
+	}
+	if !reflect.DeepEqual(sources, want) {
+		t.Fatalf("expandPermissions expect %q but returned %q", want, sources)
+	}
+	if got[0].Object != "bucket/*" || got[0].RuleID != "u1" || got[0].GroupID != "g1" {
+		t.Fatalf("expandPermissions expect rule and group details to be kept but returned %+v", got[0])
+	}
+}
+
+func TestUnitmatchesWildcard(t *testing.T) {
+	cases := []struct {
+		pattern, value string
+		want           bool
+	}{
+		{"bucket/logs", "bucket/logs", true},
+		{"bucket/logs", "bucket/logs2", false},
+		{"*", "anything/at/all", true},
+		{"bucket/*", "bucket/prod/logs", true},
+		{"bucket/*", "vm/prod", false},
+		{"*/prod/*", "bucket/prod/logs", true},
+		{"*/prod/*", "bucket/staging/logs", false},
+		{"a*b*c", "abc", true},
+		{"a*b*c", "acb", false},
+	}
+	for _, tc := range cases {
+		if got := matchesWildcard(tc.pattern, tc.value); got != tc.want {
+			t.Fatalf("matchesWildcard(%q, %q) expect %v but returned %v", tc.pattern, tc.value, tc.want, got)
+		}
+	}
+}
+
+func TestUnitevaluateAccessDenyOverridesAllow(t *testing.T) {
+	permissions := []permission{
+		{Action: "*", Object: "*", RuleName: "admin", RoleName: "admin"},
+		{Action: "DELETE", Object: "bucket/prod/*", Deny: true, RuleName: "no-prod-delete", RoleName: "guard"},
+	}
+
+	allowed, deciding := evaluateAccess(permissions, "delete", "bucket/prod/db", "")
+	if allowed || deciding == nil || deciding.RuleName != "no-prod-delete" {
+		t.Fatalf("evaluateAccess expect the deny rule to decide but returned (%v, %+v)", allowed, deciding)
+	}
+	allowed, deciding = evaluateAccess(permissions, "DELETE", "bucket/staging/db", "")
+	if !allowed || deciding == nil || deciding.RuleName != "admin" {
+		t.Fatalf("evaluateAccess expect the admin rule to allow but returned (%v, %+v)", allowed, deciding)
+	}
+}
+
+func TestUnitevaluateAccessImplicitDeny(t *testing.T) {
+	permissions := []permission{{Action: "GET", Object: "bucket/*", Service: "s3"}}
+
+	if allowed, deciding := evaluateAccess(permissions, "PUT", "bucket/a", ""); allowed || deciding != nil {
+		t.Fatalf("evaluateAccess expect implicit deny for another action but returned (%v, %+v)", allowed, deciding)
+	}
+	if allowed, deciding := evaluateAccess(permissions, "GET", "bucket/a", "compute"); allowed || deciding != nil {
+		t.Fatalf("evaluateAccess expect implicit deny for another service but returned (%v, %+v)", allowed, deciding)
+	}
+}
+
+func TestUnitgroupsHoldingRole(t *testing.T) {
+	const roleID = "11111111-1111-1111-1111-111111111111"
+	groups := []iam.IamGroup{{Uuid: "g1", Name: "ops"}, {Uuid: "g2", Name: "dev"}, {Uuid: "g3", Name: "qa"}, {Uuid: "g4", Name: "empty"}}
+	rolesByGroup := map[string][]iam.IamRole{
+		"g1": {{Uuid: "22222222-2222-2222-2222-222222222222"}, {Uuid: roleID}},
+		"g2": {{Uuid: "22222222-2222-2222-2222-222222222222"}},
+		"g3": {{Uuid: strings.ToUpper(roleID)}, {Uuid: roleID}},
+	}
+
+	got := []string{}
+	for _, g := range groupsHoldingRole(groups, rolesByGroup, roleID) {
+		got = append(got, g.Uuid)
+	}
+	if expect := []string{"g1", "g3"}; !reflect.DeepEqual(got, expect) {
+		t.Fatalf("groupsHoldingRole expect return %v but returned %v", expect, got)
+	}
+}
+
+func TestUnitbindingRows(t *testing.T) {
+	in := []map[string]string{{"bucket": "b"}, {"bucket": "a"}, {"bucket": "a"}}
+	expect := []map[string]string{{"bucket": "a"}, {"bucket": "b"}}
+
+	if got := bindingRows(in); !reflect.DeepEqual(got, expect) {
+		t.Fatalf("bindingRows expect return %v but returned %v", expect, got)
+	}
+	if got := bindingRows(nil); !reflect.DeepEqual(got, []map[string]string{{}}) {
+		t.Fatalf("bindingRows for a binding without items expect one empty map but returned %v", got)
+	}
+}
+
+func TestUnitiamGraphIsDeterministic(t *testing.T) {
+	build := func(reverse bool) *iamGraph {
+		g := newIAMGraph()
+		steps := []func(){
+			func() { g.addNode(graphNode{ID: "u1", Kind: graphKindUser, Name: "alice@example.com"}) },
+			func() { g.addNode(graphNode{ID: "g1", Kind: graphKindGroup, Name: "devs"}) },
+			func() { g.addEdge("user:u1", "group:g1", graphEdgeMemberOf) },
+			func() { g.addEdge("user:u1", "group:g1", graphEdgeMemberOf) },
+			func() { g.addNode(graphNode{ID: "r1", Kind: graphKindRole, Name: "viewer"}) },
+			func() { g.addEdge("group:g1", "role:r1", graphEdgeHasRole) },
+		}
+		for i := range steps {
+			if reverse {
+				steps[len(steps)-1-i]()
+			} else {
+				steps[i]()
+			}
+		}
+		return g
+	}
+
+	a, b := build(false), build(true)
+	ja, err := a.JSON()
+	if err != nil {
+		t.Fatalf("JSON expect no error but returned %v", err)
+	}
+	jb, _ := b.JSON()
+	if ja != jb || a.DOT() != b.DOT() {
+		t.Fatalf("iamGraph expect the same rendering regardless of insertion order")
+	}
+	if strings.Count(ja, graphEdgeMemberOf) != 1 {
+		t.Fatalf("iamGraph expect duplicate edges to be merged but returned %s", ja)
+	}
+}
+
+func TestUnitiamGraphDOT(t *testing.T) {
+	g := newIAMGraph()
+	role := g.addNode(graphNode{ID: "r1", Kind: graphKindRole, Name: `say "hi"`})
+	rule := g.addNode(graphNode{ID: "x1", Kind: graphKindRule, Name: "no-delete", Object: "bucket/*", Actions: []string{"DELETE"}, Deny: true})
+	g.addEdge(role, rule, graphEdgeHasRule)
+
+	want := `digraph iam {
+  rankdir=LR;
+  "role:r1" [label="role\nsay \"hi\"", shape=box];
+  "rule:x1" [label="rule\nno-delete\nDELETE bucket/*", shape=note, color=red];
+  "role:r1" -> "rule:x1" [label="has_rule"];
+}
+`
+	if got := g.DOT(); got != want {
+		t.Fatalf("DOT expect\n%s\nbut returned\n%s", want, got)
+	}
+}
+
+func TestUnittokenExpiry(t *testing.T) {
+	now := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	window := 30 * 24 * time.Hour
+
+	cases := map[string]struct {
+		expiresAt time.Time
+		want      string
+	}{
+		"no expiry": {time.Time{}, ""},
+		"expired":   {now.Add(-time.Hour), tokenStatusExpired},
+		"now":       {now, tokenStatusExpired},
+		"expiring":  {now.Add(10 * 24 * time.Hour), tokenStatusExpiring},
+		"healthy":   {now.Add(40 * 24 * time.Hour), ""},
+	}
+	for name, tc := range cases {
+		if got := tokenExpiry(tc.expiresAt, now, window); got != tc.want {
+			t.Fatalf("tokenExpiry for %s expect %q but returned %q", name, tc.want, got)
+		}
+	}
+}
+
+func TestUnitduplicatePublicKeys(t *testing.T) {
+	keys := []ownedKey{
+		{ID: "k1", Owner: "user:u1", Key: "ssh-ed25519 AAAA alice@laptop"},
+		{ID: "k2", Owner: "service_user:s1", Key: "ssh-ed25519 AAAA ci"},
+		{ID: "k3", Owner: "service_user:s2", Key: "ssh-ed25519 BBBB"},
+		{ID: "k4", Owner: "service_user:s2", Key: "ssh-ed25519 BBBB again"},
+		{ID: "k5", Owner: "service_user:s3", Key: ""},
+	}
+
+	want := []duplicateKey{{Key: "ssh-ed25519 AAAA", IDs: []string{"k1", "k2"}, Owners: []string{"service_user:s1", "user:u1"}}}
+	if got := duplicatePublicKeys(keys); !reflect.DeepEqual(got, want) {
+		t.Fatalf("duplicatePublicKeys expect %+v but returned %+v", want, got)
+	}
+}
+
+func TestUnitforEachConcurrently(t *testing.T) {
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	seen := make([]bool, 20)
+
+	err := forEachConcurrently(len(seen), 3, func(i int) error {
+		mu.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+		time.Sleep(time.Millisecond)
+		seen[i] = true
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("forEachConcurrently expect no error but returned %v", err)
+	}
+	if maxInFlight > 3 {
+		t.Fatalf("forEachConcurrently expect at most 3 calls in flight but saw %d", maxInFlight)
+	}
+	for i, ok := range seen {
+		if !ok {
+			t.Fatalf("forEachConcurrently expect index %d to be visited", i)
+		}
+	}
+}
+
+func TestUnitforEachConcurrentlyReturnsLowestError(t *testing.T) {
+	err := forEachConcurrently(5, 5, func(i int) error {
+		if i >= 2 {
+			return fmt.Errorf("failed %d", i)
+		}
+		return nil
+	})
+	if err == nil || err.Error() != "failed 2" {
+		t.Fatalf("forEachConcurrently expect the error of index 2 but returned %v", err)
+	}
+}
+
+func TestUnittokenFilter(t *testing.T) {
+	now := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	week := 7 * 24 * time.Hour
+	yes, no := true, false
+	never, soon, later, past := time.Time{}, now.Add(24*time.Hour), now.Add(30*24*time.Hour), now.Add(-time.Hour)
+
+	cases := []struct {
+		name   string
+		filter tokenFilter
+		want   []bool // never, soon, later, past
+	}{
+		{"no filter", tokenFilter{}, []bool{true, true, true, true}},
+		{"within a week", tokenFilter{within: &week}, []bool{false, true, false, true}},
+		{"never expires", tokenFilter{neverExpires: &yes}, []bool{true, false, false, false}},
+		{"expires", tokenFilter{neverExpires: &no}, []bool{false, true, true, true}},
+	}
+	for _, tc := range cases {
+		for i, expiresAt := range []time.Time{never, soon, later, past} {
+			if got := tc.filter.match(expiresAt, now); got != tc.want[i] {
+				t.Fatalf("tokenFilter %s for expiry %v expect %v but returned %v", tc.name, expiresAt, tc.want[i], got)
+			}
+		}
+	}
+}
+
+func TestUnitrotationDue(t *testing.T) {
+	now := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	month := 720 * time.Hour
+
+	if rotate, retire := rotationDue(now.Add(-month-time.Minute), month, time.Time{}, now); !rotate || retire {
+		t.Fatalf("rotationDue for an old token expect (true, false) but returned (%v, %v)", rotate, retire)
+	}
+	if rotate, _ := rotationDue(now.Add(-time.Hour), month, time.Time{}, now); rotate {
+		t.Fatalf("rotationDue for a fresh token expect no rotation")
+	}
+	if rotate, _ := rotationDue(now.Add(-2*month), 0, time.Time{}, now); rotate {
+		t.Fatalf("rotationDue without rotate_after expect no rotation")
+	}
+	if rotate, _ := rotationDue(time.Time{}, month, time.Time{}, now); rotate {
+		t.Fatalf("rotationDue without created_at expect no rotation")
+	}
+	if _, retire := rotationDue(now.Add(-time.Hour), month, now.Add(-time.Second), now); !retire {
+		t.Fatalf("rotationDue after the overlap window expect the previous token to retire")
+	}
+	if _, retire := rotationDue(now.Add(-time.Hour), month, now.Add(time.Hour), now); retire {
+		t.Fatalf("rotationDue within the overlap window expect the previous token to be kept")
+	}
+}
+
+func TestUnittokenRotation(t *testing.T) {
+	if _, _, ok, err := tokenRotation(nil); ok || err != nil {
+		t.Fatalf("tokenRotation without block expect (false, nil) but returned (%v, %v)", ok, err)
+	}
+
+	rotateAfter, overlap, ok, err := tokenRotation([]interface{}{map[string]interface{}{"rotate_after": "720h", "overlap": "24h"}})
+	if err != nil || !ok || rotateAfter != 720*time.Hour || overlap != 24*time.Hour {
+		t.Fatalf("tokenRotation expect (720h, 24h, true, nil) but returned (%v, %v, %v, %v)", rotateAfter, overlap, ok, err)
+	}
+	if _, _, _, err := tokenRotation([]interface{}{map[string]interface{}{"rotate_after": "soon", "overlap": "24h"}}); err == nil {
+		t.Fatalf("tokenRotation expect error for an invalid rotate_after")
+	}
+}
+
+func TestUnitplannedRotation(t *testing.T) {
+	const serviceUserID = "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+	now := time.Now().UTC()
+	cases := []struct {
+		name       string
+		createdAt  time.Time
+		previousID string
+		retireAt   time.Time
+		rotate     bool
+		retire     bool
+	}{
+		{name: "rotation due", createdAt: now.Add(-48 * time.Hour), rotate: true},
+		{name: "nothing due", createdAt: now},
+		{name: "retire due", createdAt: now, previousID: "6ba7b812-9dad-11d1-80b4-00c04fd430c8", retireAt: now.Add(-time.Hour), retire: true},
+		{name: "retire not due", createdAt: now, previousID: "6ba7b812-9dad-11d1-80b4-00c04fd430c8", retireAt: now.Add(time.Hour)},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var rotate, retire, updated bool
+			r := &schema.Resource{
+				Schema:        resourceServiceUserToken().Schema,
+				CustomizeDiff: resourceServiceUserTokenCustomizeDiff,
+				UpdateContext: func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+					updated = true
+					rotate, retire = plannedRotation(d)
+					return nil
+				},
+			}
+			retireAt := ""
+			if !tc.retireAt.IsZero() {
+				retireAt = tc.retireAt.Format(time.RFC3339)
+			}
+			state := &terraform.InstanceState{
+				ID: serviceUserID + "/6ba7b811-9dad-11d1-80b4-00c04fd430c8",
+				Attributes: map[string]string{
+					"id":                      serviceUserID + "/6ba7b811-9dad-11d1-80b4-00c04fd430c8",
+					"service_user_id":         serviceUserID,
+					"token_id":                "6ba7b811-9dad-11d1-80b4-00c04fd430c8",
+					"created_at":              tc.createdAt.Format(time.RFC3339),
+					"previous_token_id":       tc.previousID,
+					"previous_retire_at":      retireAt,
+					"rotation.#":              "1",
+					"rotation.0.rotate_after": "24h",
+					"rotation.0.overlap":      "24h",
+				},
+			}
+			cfg := terraform.NewResourceConfigRaw(map[string]interface{}{
+				"service_user_id": serviceUserID,
+				"rotation":        []interface{}{map[string]interface{}{"rotate_after": "24h", "overlap": "12h"}},
+			})
+			diff, err := r.Diff(context.Background(), state, cfg, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, diags := r.Apply(context.Background(), state, diff, nil); diags.HasError() {
+				t.Fatalf("apply failed: %v", diags)
+			}
+			if !updated || rotate != tc.rotate || retire != tc.retire {
+				t.Fatalf("plannedRotation expect (%v, %v) but returned (%v, %v), updated %v", tc.rotate, tc.retire, rotate, retire, updated)
+			}
+		})
+	}
+}
+
+func TestUnitpreviousFound(t *testing.T) {
+	tokens := []iam.IamServiceUserToken{{Uuid: "a"}, {Uuid: "b"}}
+	if !previousFound(tokens, "b") {
+		t.Fatalf("previousFound expect true for a listed token")
+	}
+	if previousFound(tokens, "c") || previousFound(tokens, "") {
+		t.Fatalf("previousFound expect false for a missing or empty token id")
+	}
+}
+
+func TestUnitparseLongDuration(t *testing.T) {
+	cases := map[string]time.Duration{
+		"90d":   90 * 24 * time.Hour,
+		"1d12h": 36 * time.Hour,
+		"36h":   36 * time.Hour,
+		"0s":    0,
+	}
+	for in, want := range cases {
+		got, err := parseLongDuration(in)
+		if err != nil || got != want {
+			t.Fatalf("parseLongDuration(%q) expect (%v, nil) but returned (%v, %v)", in, want, got, err)
+		}
+	}
+	for _, in := range []string{"", "d", "xd", "-1d", "90days", "1.5d"} {
+		if _, err := parseLongDuration(in); err == nil {
+			t.Fatalf("parseLongDuration(%q) expect error", in)
+		}
+	}
+}
+
+func TestUnitparseExpiry(t *testing.T) {
+	want := time.Date(2025, 9, 30, 0, 0, 0, 0, time.UTC)
+	for _, in := range []string{"2025-09-30", "2025-09-30T00:00:00Z", "2025-09-30T03:30:00+03:30"} {
+		got, err := parseExpiry(in)
+		if err != nil || !got.Equal(want) {
+			t.Fatalf("parseExpiry(%q) expect %v but returned (%v, %v)", in, want, got, err)
+		}
+	}
+	if _, err := parseExpiry("30/09/2025"); err == nil {
+		t.Fatalf("parseExpiry expect error for an unsupported format")
+	}
+	if !suppressEquivalentExpiry("expires_at", "2025-09-30T00:00:00Z", "2025-09-30", nil) {
+		t.Fatalf("suppressEquivalentExpiry expect a date and its timestamp to be equivalent")
+	}
+	if suppressEquivalentExpiry("expires_at", "2025-09-30T00:00:00Z", "2025-10-01", nil) {
+		t.Fatalf("suppressEquivalentExpiry expect different days to differ")
+	}
+}
+
+func TestUnitrenewalDue(t *testing.T) {
+	now := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	week := 7 * 24 * time.Hour
+
+	if !renewalDue(now.Add(6*24*time.Hour), week, now) {
+		t.Fatalf("renewalDue within renew_before expect true")
+	}
+	if !renewalDue(now.Add(-time.Hour), week, now) {
+		t.Fatalf("renewalDue for an expired token expect true")
+	}
+	if renewalDue(now.Add(8*24*time.Hour), week, now) {
+		t.Fatalf("renewalDue before renew_before expect false")
+	}
+	if renewalDue(time.Time{}, week, now) {
+		t.Fatalf("renewalDue for a token without expiry expect false")
+	}
+}
+
+func TestUnitsetUserTokenStateRemovesMissingToken(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourceUserToken().Schema, map[string]interface{}{"name": "ci"})
+	d.SetId("55555555-5555-5555-5555-555555555555")
+
+	if diags := setUserTokenState(context.Background(), d, nil, client.ErrNotFound); diags.HasError() {
+		t.Fatalf("setUserTokenState expect no error for a missing token but returned %v", diags)
+	}
+	if d.Id() != "" {
+		t.Fatalf("setUserTokenState expect the missing token to be removed but id is %q", d.Id())
+	}
+}
+
+func TestUnitsetUserTokenStateKeepsTokenOnError(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourceUserToken().Schema, map[string]interface{}{"name": "ci"})
+	d.SetId("55555555-5555-5555-5555-555555555555")
+
+	diags := setUserTokenState(context.Background(), d, nil, fmt.Errorf("failed to list user tokens: unexpected status code 500"))
+	if !diags.HasError() {
+		t.Fatal("setUserTokenState expect an error when the tokens cannot be listed")
+	}
+	if d.Id() == "" {
+		t.Fatal("setUserTokenState expect the token to stay in state when the tokens cannot be listed")
+	}
+}
+
+func TestUnitsetUserTokenStateExpiry(t *testing.T) {
+	expiresAt := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	cases := []struct {
+		expiresAt time.Time
+		expect    string
+	}{
+		{time.Time{}, ""},
+		{expiresAt, "2030-01-02T03:04:05Z"},
+	}
+	for _, tc := range cases {
+		d := schema.TestResourceDataRaw(t, resourceUserToken().Schema, map[string]interface{}{"name": "ci"})
+		d.SetId("55555555-5555-5555-5555-555555555555")
+
+		if diags := setUserTokenState(context.Background(), d, &iam.IamUserToken{Name: "ci", ExpiresAt: tc.expiresAt}, nil); diags.HasError() {
+			t.Fatalf("setUserTokenState returned %v", diags)
+		}
+		for _, key := range []string{"expires_at", "expire_at"} {
+			if got := d.Get(key).(string); got != tc.expect {
+				t.Errorf("setUserTokenState expect %s %q but set %q", key, tc.expect, got)
+			}
+		}
+	}
+}
+
+func TestUnitresourceUserTokenImport(t *testing.T) {
+	r := resourceUserToken()
+	meta := &client.Client{UserID: "8f7e9a2c-1b3d-4e5f-a6b7-c8d9e0f1a2b3"}
+
+	d := r.TestResourceData()
+	d.SetId("55555555-5555-5555-5555-555555555555")
+	got, err := resourceUserTokenImport(context.Background(), d, meta)
+	if err != nil {
+		t.Fatalf("resourceUserTokenImport returned %v", err)
+	}
+	if len(got) != 1 || got[0].Id() != "55555555-5555-5555-5555-555555555555" || got[0].Get("value").(string) != "" || !got[0].Get("imported").(bool) {
+		t.Fatalf("resourceUserTokenImport expect the imported token with an empty value but returned %v", got)
+	}
+
+	d = r.TestResourceData()
+	d.SetId("not-a-uuid")
+	if _, err := resourceUserTokenImport(context.Background(), d, meta); err == nil {
+		t.Fatal("resourceUserTokenImport expect an error for an id which is not a UUID")
+	}
+
+	d = r.TestResourceData()
+	d.SetId("55555555-5555-5555-5555-555555555555")
+	if _, err := resourceUserTokenImport(context.Background(), d, &client.Client{}); err == nil {
+		t.Fatal("resourceUserTokenImport expect an error without the provider user_id")
+	}
+}
+
+func TestUnitsuppressImportedExpiresIn(t *testing.T) {
+	r := resourceUserToken()
+	state := &terraform.InstanceState{
+		ID: "55555555-5555-5555-5555-555555555555",
+		Attributes: map[string]string{
+			"id":         "55555555-5555-5555-5555-555555555555",
+			"name":       "ci",
+			"expires_at": time.Now().Add(60 * 24 * time.Hour).UTC().Format(time.RFC3339),
+			"expire_at":  time.Now().Add(60 * 24 * time.Hour).UTC().Format(time.RFC3339),
+			"value":      "",
+			"imported":   "true",
+		},
+	}
+	cfg := terraform.NewResourceConfigRaw(map[string]interface{}{"name": "ci", "expires_in": "90d", "renew_before": "7d"})
+	diff, err := r.Diff(context.Background(), state, cfg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff != nil && diff.RequiresNew() {
+		t.Fatalf("expect an imported token with expires_in in the configuration to be kept but the plan replaces it: %v", diff)
+	}
+
+	state.Attributes["expires_at"] = time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
+	state.Attributes["expire_at"] = state.Attributes["expires_at"]
+	if diff, err = r.Diff(context.Background(), state, cfg, nil); err != nil {
+		t.Fatal(err)
+	}
+	if diff == nil || !diff.RequiresNew() {
+		t.Fatal("expect an imported token within renew_before of its expiry to be replaced")
+	}
+	if a := diff.Attributes["expires_in"]; a == nil || a.New != "90d" {
+		t.Fatalf("expect the replacement to be created with expires_in but planned %v", a)
+	}
+}
+
+func TestUnitsuppressImportedExpiresInReplacesCreatedToken(t *testing.T) {
+	r := resourceUserToken()
+	expiresAt := time.Now().Add(60 * 24 * time.Hour).UTC().Format(time.RFC3339)
+	state := &terraform.InstanceState{
+		ID: "55555555-5555-5555-5555-555555555555",
+		Attributes: map[string]string{
+			"id":         "55555555-5555-5555-5555-555555555555",
+			"name":       "ci",
+			"expires_at": expiresAt,
+			"expire_at":  expiresAt,
+			"value":      "secret",
+			"imported":   "false",
+		},
+	}
+
+	cfg := terraform.NewResourceConfigRaw(map[string]interface{}{"name": "ci", "expires_in": "90d"})
+	diff, err := r.Diff(context.Background(), state, cfg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff == nil || !diff.RequiresNew() {
+		t.Fatalf("expect a created token given expires_in to be replaced but planned %v", diff)
+	}
+
+	state.Attributes["expires_in"] = "30d"
+	if diff, err = r.Diff(context.Background(), state, cfg, nil); err != nil {
+		t.Fatal(err)
+	}
+	if diff == nil || !diff.RequiresNew() {
+		t.Fatalf("expect a created token given a new expires_in to be replaced but planned %v", diff)
+	}
+}
+
+func TestUnitdriftedSharedItemsWarnsWhenMembersDisagree(t *testing.T) {
+	remote := map[string][]map[string]string{
+		"a": {{"bucket": "x"}},
+		"b": {{"bucket": "y"}},
+	}
+
+	items, diags := driftedSharedItems([]string{"a", "b"}, remote)
+	if diags.HasError() || len(diags) != 1 || !reflect.DeepEqual(items, map[string]string{"bucket": "x"}) {
+		t.Fatalf("driftedSharedItems expect the items of a with one warning but returned (%v, %v)", items, diags)
+	}
+	if !strings.Contains(diags[0].Detail, "a: ") || !strings.Contains(diags[0].Detail, "b: ") {
+		t.Fatalf("driftedSharedItems expect every drifted member in the warning but returned %q", diags[0].Detail)
+	}
+}
+
+func TestUnitdriftedSharedItemsAgreeing(t *testing.T) {
+	remote := map[string][]map[string]string{
+		"a": {{"bucket": "x"}},
+		"b": {{"bucket": "x"}},
+	}
+
+	items, diags := driftedSharedItems([]string{"a", "b"}, remote)
+	if len(diags) != 0 || !reflect.DeepEqual(items, map[string]string{"bucket": "x"}) {
+		t.Fatalf("driftedSharedItems expect the shared items without warning but returned (%v, %v)", items, diags)
+	}
+}
+
+func TestUnitrebindableIDs(t *testing.T) {
+	ids := []string{"a", "b", "c"}
+	managed := []string{"b", "x"}
+
+	if got := rebindableIDs(ids, managed, false); !reflect.DeepEqual(got, []string{"b"}) {
+		t.Fatalf("rebindableIDs expect only managed ids but returned %q", got)
+	}
+	if got := rebindableIDs(ids, managed, true); !reflect.DeepEqual(got, ids) {
+		t.Fatalf("rebindableIDs in exclusive mode expect every id but returned %q", got)
+	}
+	if got := rebindableIDs(ids, nil, false); len(got) != 0 {
+		t.Fatalf("rebindableIDs during create expect no pre-existing id but returned %q", got)
+	}
+}
+
+func TestUnitflattenBindingsKeepsDesiredForUnmanaged(t *testing.T) {
+	desired := map[string]map[string]string{"a": {"namespace": "dev"}}
+	remote := map[string][]map[string]string{"a": {{"namespace": "prod"}}}
+	expect := []interface{}{map[string]interface{}{"id": "a", "items": map[string]string{"namespace": "dev"}}}
+
+	if got, err := flattenBindings([]string{"a"}, nil, desired, remote); err != nil || !reflect.DeepEqual(got, expect) {
+		t.Fatalf("flattenBindings expect the desired items of an unmanaged member but returned (%v, %v)", got, err)
+	}
+}
+
+func TestUnitcheckPlannedItemsContinuesAfterInvalidRoleID(t *testing.T) {
+	roleID := "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+	possible := map[string][]string{"zone": {"a"}}
+	planned := []roleItems{
+		{roleID: "not-a-uuid", items: map[string]string{"zone": "a"}},
+		{roleID: roleID, items: map[string]string{"bucket": "b"}},
+	}
+
+	lookups := 0
+	err := checkPlannedItems(planned, func(roleUUID uuid.UUID) (*map[string][]string, error) {
+		lookups++
+		return &possible, nil
+	})
+	if err == nil || lookups != 1 {
+		t.Fatalf("checkPlannedItems expect the role after an invalid id to be checked but returned (%v) after %d lookups", err, lookups)
+	}
+}
+
+func TestUnitplannedRoleItemsIncludesResolvedNames(t *testing.T) {
+	roleID := "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+	var planned []roleItems
+	r := &schema.Resource{
+		Schema: resourceGroupRole().Schema,
+		CustomizeDiff: func(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+			if err := d.SetNew("resolved_role_ids", map[string]interface{}{"viewer": roleID}); err != nil {
+				return err
+			}
+			planned, _ = plannedRoleItems(d, "", "role_ids", "resolved_role_ids")
+			return nil
+		},
+	}
+	cfg := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"group_id":   "6ba7b811-9dad-11d1-80b4-00c04fd430c8",
+		"role_names": []interface{}{"viewer"},
+		"items":      map[string]interface{}{"zone": "a"},
+	})
+	if _, err := r.Diff(context.Background(), nil, cfg, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	expect := []roleItems{{roleID: roleID, items: map[string]string{"zone": "a"}}}
+	if !reflect.DeepEqual(planned, expect) {
+		t.Fatalf("plannedRoleItems expect %v but returned %v", expect, planned)
+	}
+}
+
+func TestUnitserviceUserTokenExpiryDiff(t *testing.T) {
+	const serviceUserID = "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+	now := time.Now().UTC()
+	cases := []struct {
+		name        string
+		createdAt   time.Time
+		expiresAt   time.Time
+		config      map[string]interface{}
+		computed    bool
+		requiresNew bool
+	}{
+		{
+			name:      "rotation with expires_in renews the expiry in place",
+			createdAt: now.Add(-48 * time.Hour),
+			expiresAt: now.Add(30 * 24 * time.Hour),
+			config:    map[string]interface{}{"expires_in": "90d", "rotation": []interface{}{map[string]interface{}{"rotate_after": "24h"}}},
+			computed:  true,
+		},
+		{
+			name:      "rotation with expires_at keeps the expiry",
+			createdAt: now.Add(-48 * time.Hour),
+			expiresAt: now.Add(30 * 24 * time.Hour).Truncate(time.Second),
+			config:    map[string]interface{}{"expires_at": now.Add(30 * 24 * time.Hour).Truncate(time.Second).Format(time.RFC3339), "rotation": []interface{}{map[string]interface{}{"rotate_after": "24h"}}},
+		},
+		{
+			name:        "configured expiry change replaces the token",
+			createdAt:   now,
+			expiresAt:   now.Add(30 * 24 * time.Hour),
+			config:      map[string]interface{}{"expires_at": "2099-01-01"},
+			requiresNew: true,
+		},
+		{
+			name:        "renewal replaces the token",
+			createdAt:   now,
+			expiresAt:   now.Add(24 * time.Hour),
+			config:      map[string]interface{}{"expires_in": "90d", "renew_before": "7d"},
+			computed:    true,
+			requiresNew: true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := resourceServiceUserToken()
+			state := &terraform.InstanceState{
+				ID: serviceUserID + "/6ba7b811-9dad-11d1-80b4-00c04fd430c8",
+				Attributes: map[string]string{
+					"id":              serviceUserID + "/6ba7b811-9dad-11d1-80b4-00c04fd430c8",
+					"service_user_id": serviceUserID,
+					"token_id":        "6ba7b811-9dad-11d1-80b4-00c04fd430c8",
+					"created_at":      tc.createdAt.Format(time.RFC3339),
+					"expires_at":      tc.expiresAt.Format(time.RFC3339),
+				},
+			}
+			for k, v := range tc.config {
+				if s, ok := v.(string); ok {
+					state.Attributes[k] = s
+				}
+			}
+			if rotation, ok := tc.config["rotation"]; ok {
+				state.Attributes["rotation.#"] = "1"
+				state.Attributes["rotation.0.rotate_after"] = rotation.([]interface{})[0].(map[string]interface{})["rotate_after"].(string)
+				state.Attributes["rotation.0.overlap"] = "24h"
+			}
+			state.Attributes["expires_at"] = tc.expiresAt.Format(time.RFC3339)
+			cfg := map[string]interface{}{"service_user_id": serviceUserID}
+			for k, v := range tc.config {
+				cfg[k] = v
+			}
+			diff, err := r.Diff(context.Background(), state, terraform.NewResourceConfigRaw(cfg), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			computed := false
+			if diff != nil && diff.Attributes["expires_at"] != nil {
+				computed = diff.Attributes["expires_at"].NewComputed
+			}
+			requiresNew := diff != nil && diff.RequiresNew()
+			if computed != tc.computed || requiresNew != tc.requiresNew {
+				t.Fatalf("expires_at expect computed %v and requires new %v but got %v and %v", tc.computed, tc.requiresNew, computed, requiresNew)
+			}
+		})
+	}
+}
+
+func TestUnittokenExpiresInReplacesToken(t *testing.T) {
+	const serviceUserID = "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+	const tokenID = "6ba7b811-9dad-11d1-80b4-00c04fd430c8"
+	expiresAt := time.Now().Add(60 * 24 * time.Hour).UTC().Format(time.RFC3339)
+	resources := map[string]struct {
+		resource *schema.Resource
+		state    map[string]string
+		config   map[string]interface{}
+	}{
+		"sotoon_iam_user_token": {
+			resource: resourceUserToken(),
+			state:    map[string]string{"id": tokenID, "name": "ci", "expires_at": expiresAt, "expire_at": expiresAt, "imported": "false"},
+			config:   map[string]interface{}{"name": "ci"},
+		},
+		"sotoon_iam_service_user_token": {
+			resource: resourceServiceUserToken(),
+			state:    map[string]string{"id": serviceUserID + "/" + tokenID, "service_user_id": serviceUserID, "token_id": tokenID, "expires_at": expiresAt},
+			config:   map[string]interface{}{"service_user_id": serviceUserID},
+		},
+	}
+	cases := []struct {
+		name      string
+		expiresIn string
+		stateIn   string
+	}{
+		{name: "expires_in added", expiresIn: "90d"},
+		{name: "expires_in changed", expiresIn: "90d", stateIn: "30d"},
+	}
+	for resourceName, rc := range resources {
+		for _, tc := range cases {
+			t.Run(resourceName+" "+tc.name, func(t *testing.T) {
+				state := &terraform.InstanceState{ID: rc.state["id"], Attributes: map[string]string{}}
+				for k, v := range rc.state {
+					state.Attributes[k] = v
+				}
+				if tc.stateIn != "" {
+					state.Attributes["expires_in"] = tc.stateIn
+				}
+				cfg := map[string]interface{}{"expires_in": tc.expiresIn}
+				for k, v := range rc.config {
+					cfg[k] = v
+				}
+				diff, err := rc.resource.Diff(context.Background(), state, terraform.NewResourceConfigRaw(cfg), nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if diff == nil || !diff.RequiresNew() {
+					t.Fatalf("expect the token to be replaced but planned %v", diff)
+				}
+			})
+		}
+	}
+}
@@ -45,19 +45,24 @@ func Provider() *schema.Provider {
 			},
 		},
 		ResourcesMap: map[string]*schema.Resource{
-			"sotoon_iam_user":                    resourceUser(),
-			"sotoon_iam_group":                   resourceGroup(),
-			"sotoon_iam_user_token":              resourceUserToken(),
-			"sotoon_iam_user_public_key":         resourceUserPublicKey(),
-			"sotoon_iam_user_group_membership":   resourceUserGroupMembership(),
-			"sotoon_iam_group_role":              resourceGroupRole(),
-			"sotoon_iam_service_user_group":      resourceGroupServiceUser(),
-			"sotoon_iam_service_user":            resourceServiceUser(),
-			"sotoon_iam_service_user_token":      resourceServiceUserToken(),
-			"sotoon_iam_service_user_public_key": resourceServiceUserPublicKey(),
-			"sotoon_iam_service_user_role":       resourceServiceUserRole(),
-			"sotoon_iam_user_role":               resourceUserRole(),
-			"sotoon_iam_role":                    resourceRole(),
+			"sotoon_iam_user":                      resourceUser(),
+			"sotoon_iam_group":                     resourceGroup(),
+			"sotoon_iam_user_token":                resourceUserToken(),
+			"sotoon_iam_user_public_key":           resourceUserPublicKey(),
+			"sotoon_iam_user_group_membership":     resourceUserGroupMembership(),
+			"sotoon_iam_group_role":                resourceGroupRole(),
+			"sotoon_iam_service_user_group":        resourceGroupServiceUser(),
+			"sotoon_iam_service_user":              resourceServiceUser(),
+			"sotoon_iam_service_user_token":        resourceServiceUserToken(),
+			"sotoon_iam_service_user_public_key":   resourceServiceUserPublicKey(),
+			"sotoon_iam_service_user_role":         resourceServiceUserRole(),
+			"sotoon_iam_user_role":                 resourceUserRole(),
+			"sotoon_iam_role":                      resourceRole(),
+			"sotoon_iam_group_member":              resourceGroupMember(),
+			"sotoon_iam_role_user_binding":         resourceRoleUserBinding(),
+			"sotoon_iam_role_group_binding":        resourceRoleGroupBinding(),
+			"sotoon_iam_role_service_user_binding": resourceRoleServiceUserBinding(),
+			"sotoon_iam_user_invitation_batch":     resourceUserInvitationBatch(),
 		},
 		DataSourcesMap: map[string]*schema.Resource{
 			"sotoon_iam_users":                    dataSourceUsers(),
@@ -77,6 +82,17 @@ func Provider() *schema.Provider {
 			"sotoon_iam_rules":                    dataSourceRules(),
 			"sotoon_iam_user_roles":               dataSourceUserRoles(),
 			"sotoon_iam_service_user_roles":       dataSourceServiceUserRoles(),
+			"sotoon_iam_role_items":               dataSourceRoleItems(),
+			"sotoon_iam_role":                     dataSourceRole(),
+			"sotoon_iam_rule":                     dataSourceRule(),
+			"sotoon_iam_group":                    dataSourceGroup(),
+			"sotoon_iam_service_user":             dataSourceServiceUser(),
+			"sotoon_iam_effective_permissions":    dataSourceEffectivePermissions(),
+			"sotoon_iam_access_check":             dataSourceAccessCheck(),
+			"sotoon_iam_role_members":             dataSourceRoleMembers(),
+			"sotoon_iam_graph":                    dataSourceGraph(),
+			"sotoon_iam_hygiene_report":           dataSourceHygieneReport(),
+			"sotoon_iam_tokens":                   dataSourceTokens(),
 		},
 		ConfigureContextFunc: providerConfigure,
 	}
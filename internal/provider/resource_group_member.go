@@ -0,0 +1,111 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	uuid "github.com/satori/go.uuid"
+	"github.com/sotoon/terraform-provider-sotoon/internal/client"
+)
+
+func resourceGroupMember() *schema.Resource {
+	return &schema.Resource{
+		Description:   "Adds a single user to a group within a Sotoon workspace.",
+		CreateContext: resourceGroupMemberCreate,
+		ReadContext:   resourceGroupMemberRead,
+		DeleteContext: resourceGroupMemberDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: importTwoPartID("group_id", "user_id"),
+		},
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Identifier in the form `<group_id>/<user_id>`.",
+			},
+			"group_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Group UUID.",
+			},
+			"user_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "User UUID to add to the group.",
+			},
+		},
+	}
+}
+
+func resourceGroupMemberCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client.Client)
+
+	groupID := d.Get("group_id").(string)
+	groupUUID, err := uuid.FromString(groupID)
+	if err != nil {
+		return diag.Errorf("invalid group_id: %s", err)
+	}
+	userUUID, err := uuid.FromString(d.Get("user_id").(string))
+	if err != nil {
+		return diag.Errorf("invalid user_id: %s", err)
+	}
+
+	if _, err := c.BulkAddUsersToGroup(ctx, groupUUID, []string{userUUID.String()}); err != nil {
+		return diag.Errorf("add user %s to group %s: %s", userUUID, groupID, err)
+	}
+
+	d.SetId(fmt.Sprintf("%s/%s", groupUUID.String(), userUUID.String()))
+	return resourceGroupMemberRead(ctx, d, meta)
+}
+
+func resourceGroupMemberRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client.Client)
+	groupUUID, userUUID, err := parseTwoPartID(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	usersList, err := c.GetAllGroupUserList(ctx, &groupUUID)
+	if err != nil {
+		if errors.Is(err, client.ErrNotFound) {
+			d.SetId("")
+			return nil
+		}
+		return diag.Errorf("read group users: %s", err)
+	}
+
+	for _, u := range usersList {
+		if u.Uuid == userUUID.String() {
+			if err := d.Set("group_id", groupUUID.String()); err != nil {
+				return diag.FromErr(fmt.Errorf("failed to set group_id: %w", err))
+			}
+			if err := d.Set("user_id", userUUID.String()); err != nil {
+				return diag.FromErr(fmt.Errorf("failed to set user_id: %w", err))
+			}
+			return nil
+		}
+	}
+
+	tflog.Info(ctx, "User is no longer a member of the group", map[string]interface{}{"id": d.Id()})
+	d.SetId("")
+	return nil
+}
+
+func resourceGroupMemberDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client.Client)
+	groupUUID, userUUID, err := parseTwoPartID(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if err := c.RemoveUserFromGroup(ctx, groupUUID.String(), userUUID.String()); err != nil {
+		return diag.Errorf("failed to remove user %s from group %s: %s", userUUID, groupUUID, err)
+	}
+	d.SetId("")
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	uuid "github.com/satori/go.uuid"
 	iam "github.com/sotoon/sotoon-sdk-go/sdk/core/iam_v1"
@@ -17,7 +18,18 @@ func resourceGroupRole() *schema.Resource {
 		Description:   "Binds one or more IAM roles to a group within a Sotoon workspace.",
 		CreateContext: resourceGroupRoleCreate,
 		ReadContext:   resourceGroupRoleRead,
+		UpdateContext: resourceGroupRoleUpdate,
 		DeleteContext: resourceGroupRoleDelete,
+		CustomizeDiff: customdiff.All(
+			customdiff.ComputedIf("bindings_hash", func(ctx context.Context, d *schema.ResourceDiff, meta interface{}) bool {
+				return d.HasChanges("role_ids", "role_names", "scope", "binding")
+			}),
+			customdiff.ComputedIf("managed_role_ids", func(ctx context.Context, d *schema.ResourceDiff, meta interface{}) bool {
+				return d.HasChanges("role_ids", "role_names", "scope", "binding", "exclusive")
+			}),
+			roleLookup.resolveSetDiff("role_names", "resolved_role_ids"),
+			validateBindingItems("", "role_ids", "resolved_role_ids"),
+		),
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
@@ -34,19 +46,49 @@ func resourceGroupRole() *schema.Resource {
 				Description: "Group UUID.",
 			},
 			"role_ids": {
-				Type:        schema.TypeSet,
-				Required:    true,
-				MinItems:    1,
-				ForceNew:    true,
+				Type:          schema.TypeSet,
+				Optional:      true,
+				MinItems:      1,
+				AtLeastOneOf:  []string{"role_ids", "role_names", "binding"},
+				ConflictsWith: []string{"binding"},
+				Elem:          &schema.Schema{Type: schema.TypeString},
+				Description:   "Set of Role UUIDs to bind to the group.",
+			},
+			"role_names": {
+				Type:          schema.TypeSet,
+				Optional:      true,
+				MinItems:      1,
+				AtLeastOneOf:  []string{"role_ids", "role_names", "binding"},
+				ConflictsWith: []string{"binding"},
+				Elem:          &schema.Schema{Type: schema.TypeString},
+				Description:   "Names of roles to bind to the group with the shared `items`, resolved to UUIDs at plan time. Can be combined with `role_ids`.",
+			},
+			"scope": nameScopeSchema("role_names"),
+			"resolved_role_ids": {
+				Type:        schema.TypeMap,
+				Computed:    true,
 				Elem:        &schema.Schema{Type: schema.TypeString},
-				Description: "Set of Role UUIDs to bind to the group.",
+				Description: "Role UUID of every name in `role_names`. A name keeps its UUID for as long as it stays in `role_names`.",
 			},
+			"binding": roleBindingSchema("Role UUID to bind to the group.", "role_ids", "role_names"),
 			"items": {
-				Type:        schema.TypeMap,
+				Type:          schema.TypeMap,
+				Optional:      true,
+				ConflictsWith: []string{"binding"},
+				Elem:          &schema.Schema{Type: schema.TypeString},
+				Description:   "Optional key/value items to pass to the bind API for each role. Items are read back for every bound role; a difference shows up as drift and the affected roles are rebound in place.",
+			},
+			"exclusive": {
+				Type:        schema.TypeBool,
 				Optional:    true,
-				ForceNew:    true,
+				Default:     false,
+				Description: "If true, `role_ids` is authoritative: bindings added outside of Terraform are reported as drift and removed on apply.",
+			},
+			"managed_role_ids": {
+				Type:        schema.TypeSet,
+				Computed:    true,
 				Elem:        &schema.Schema{Type: schema.TypeString},
-				Description: "Optional key/value items to pass to the bind API for each role.",
+				Description: "Role UUIDs this resource bound to the group. Only these are unbound on destroy; bindings that existed before are left in place.",
 			},
 			"bindings_hash": {
 				Type:        schema.TypeString,
@@ -66,42 +108,25 @@ func resourceGroupRoleCreate(ctx context.Context, d *schema.ResourceData, meta i
 		return diag.Errorf("invalid group_id %q: %s", groupID, err)
 	}
 
-	sortedRoleIds := uniqueSorted(fromSchemaSetToStrings(d.Get("role_ids").(*schema.Set)))
-
-	rolesList, err := c.GetWorkspaceGroupRoleList(ctx, c.WorkspaceUUID, &groupUUID)
+	nameRoleIds, err := roleLookup.resolveSet(ctx, c, d, "role_names", "resolved_role_ids")
 	if err != nil {
-		return diag.Errorf("read group roles: %s ", err)
+		return diag.FromErr(err)
 	}
-	remoteRolesID := make([]string, 0, len(rolesList))
-	for _, r := range rolesList {
-		remoteRolesID = append(remoteRolesID, r.Uuid)
-	}
-	remoteRolesID = uniqueSorted(remoteRolesID)
-
-	items := map[string]string{}
-	if raw, ok := d.GetOk("items"); ok && raw != nil {
-		for k, v := range raw.(map[string]interface{}) {
-			items[k] = fmt.Sprintf("%v", v)
-		}
+	desired := withSharedBindings(d, desiredBindings(d, "role_ids"), nameRoleIds)
+	toAddList, _, err := syncGroupRoleBindings(ctx, c, groupUUID, desired, nil, d.Get("exclusive").(bool))
+	if err != nil {
+		return diag.FromErr(err)
 	}
 
-	toAddList := diff(toSet(sortedRoleIds), toSet(remoteRolesID))
-	if len(toAddList) > 0 {
-		rolesWithItems := make([]iam.IamRoleItem, 0, len(toAddList))
-		for _, id := range toAddList {
-			rolesWithItems = append(rolesWithItems, iam.IamRoleItem{
-				RoleUuid:  id,
-				ItemsList: &[]map[string]string{items}})
-		}
-		if err := c.BulkAddRolesToGroup(ctx, &groupUUID, rolesWithItems); err != nil {
-			return diag.Errorf("bulk bind roles to group %s failed: %s", groupUUID.String(), err)
-		}
+	if err := d.Set("managed_role_ids", toAddList); err != nil {
+		return diag.FromErr(fmt.Errorf("failed to set managed_role_ids: %w", err))
 	}
 
-	bindHash := hashOfIDs(sortedRoleIds)
+	bindHash := hashOfIDs(bindingIDs(desired))
 	if err := d.Set("bindings_hash", bindHash); err != nil {
 		return diag.FromErr(fmt.Errorf("failed to set bindings_hash: %w", err))
 	}
+	// the id is fixed at creation, bindings_hash follows later in-place updates
 	d.SetId(groupUUID.String() + ":" + bindHash)
 
 	return resourceGroupRoleRead(ctx, d, meta)
@@ -117,7 +142,9 @@ func resourceGroupRoleRead(ctx context.Context, d *schema.ResourceData, meta int
 		return nil
 	}
 
-	sortedRoleIds := uniqueSorted(fromSchemaSetToStrings(d.Get("role_ids").(*schema.Set)))
+	nameRoleIds := resolvedIDs(fromSchemaSetToStrings(d.Get("role_names").(*schema.Set)), stringMap(d.Get("resolved_role_ids").(map[string]interface{})))
+	desired := withSharedBindings(d, desiredBindings(d, "role_ids"), nameRoleIds)
+	sortedRoleIds := bindingIDs(desired)
 
 	rolesList, err := c.GetWorkspaceGroupRoleList(ctx, c.WorkspaceUUID, &groupUUID)
 	if err != nil {
@@ -125,32 +152,153 @@ func resourceGroupRoleRead(ctx context.Context, d *schema.ResourceData, meta int
 	}
 
 	remoteRoles := make([]string, 0, len(rolesList))
+	remoteItems := make(map[string][]map[string]string, len(rolesList))
 	for _, r := range rolesList {
 		if r.Uuid != "" {
 			remoteRoles = append(remoteRoles, r.Uuid)
+			remoteItems[r.Uuid] = append(remoteItems[r.Uuid], r.Items...)
 			continue
 		}
 	}
 	remoteRoles = uniqueSorted(remoteRoles)
 
-	eff := intersect(toSet(sortedRoleIds), toSet(remoteRoles))
-	effective := uniqueSorted(setKeys(eff))
+	effective := effectiveIDs(sortedRoleIds, remoteRoles, d.Get("exclusive").(bool))
 
-	if err := d.Set("role_ids", effective); err != nil {
-		return diag.FromErr(fmt.Errorf("failed to set role_ids: %w", err))
+	managed := effective
+	if tracksManagedIDs(d, "managed_role_ids") {
+		managed = uniqueSorted(setKeys(intersect(toSet(fromSchemaSetToStrings(d.Get("managed_role_ids").(*schema.Set))), toSet(remoteRoles))))
 	}
+	// only members this resource bound, or every member in exclusive mode, are rebound on drift
+	rebindable := rebindableIDs(effective, managed, d.Get("exclusive").(bool))
 
-	bindHash := d.Get("bindings_hash").(string)
-	if bindHash == "" {
-		bindHash = hashOfIDs(effective)
-		if err := d.Set("bindings_hash", bindHash); err != nil {
+	var diags diag.Diagnostics
+	if _, ok := d.GetOk("binding"); ok {
+		bindings, err := flattenBindings(effective, rebindable, desired, remoteItems)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		if err := d.Set("binding", bindings); err != nil {
+			return diag.FromErr(fmt.Errorf("failed to set binding: %w", err))
+		}
+	} else {
+		nameOnlyIds, err := readResolved(d, "role_names", "resolved_role_ids", "role_ids", remoteRoles)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		if err := d.Set("role_ids", diff(toSet(effective), toSet(nameOnlyIds))); err != nil {
+			return diag.FromErr(fmt.Errorf("failed to set role_ids: %w", err))
+		}
+
+		if drifted := driftedItemIDs(rebindable, remoteItems, stringMap(d.Get("items").(map[string]interface{}))); len(drifted) > 0 {
+			var items map[string]string
+			items, diags = driftedSharedItems(drifted, remoteItems)
+			if diags.HasError() {
+				return diags
+			}
+			if err := d.Set("items", items); err != nil {
+				return diag.FromErr(fmt.Errorf("failed to set items: %w", err))
+			}
+		}
+	}
+
+	if err := d.Set("managed_role_ids", managed); err != nil {
+		return diag.FromErr(fmt.Errorf("failed to set managed_role_ids: %w", err))
+	}
+
+	if d.Get("bindings_hash").(string) == "" {
+		if err := d.Set("bindings_hash", hashOfIDs(effective)); err != nil {
 			return diag.FromErr(fmt.Errorf("failed to set bindings_hash: %w", err))
 		}
 	}
 
-	d.SetId(groupUUID.String() + ":" + bindHash)
 	tflog.Info(ctx, "Reading group role", map[string]interface{}{"id": d.Id(), "group_id": groupID, "have": len(effective)})
-	return nil
+	return diags
+}
+
+func resourceGroupRoleUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client.Client)
+
+	groupID := d.Get("group_id").(string)
+	groupUUID, err := uuid.FromString(groupID)
+	if err != nil {
+		return diag.Errorf("invalid group_id %q: %s", groupID, err)
+	}
+
+	if d.HasChanges("role_ids", "role_names", "scope", "binding", "items", "exclusive") {
+		oldManaged, _ := d.GetChange("managed_role_ids")
+		managedIds := fromSchemaSetToStrings(oldManaged.(*schema.Set))
+		nameRoleIds, err := roleLookup.resolveSet(ctx, c, d, "role_names", "resolved_role_ids")
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		desired := withSharedBindings(d, desiredBindings(d, "role_ids"), nameRoleIds)
+
+		toAddList, toRemoveList, err := syncGroupRoleBindings(ctx, c, groupUUID, desired, managedIds, d.Get("exclusive").(bool))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+
+		if err := d.Set("managed_role_ids", managedAfter(managedIds, toAddList, toRemoveList)); err != nil {
+			return diag.FromErr(fmt.Errorf("failed to set managed_role_ids: %w", err))
+		}
+		if err := d.Set("bindings_hash", hashOfIDs(bindingIDs(desired))); err != nil {
+			return diag.FromErr(fmt.Errorf("failed to set bindings_hash: %w", err))
+		}
+		tflog.Info(ctx, "Updated group role", map[string]interface{}{"id": d.Id(), "added": len(toAddList), "removed": len(toRemoveList)})
+	}
+
+	return resourceGroupRoleRead(ctx, d, meta)
+}
+
+// syncGroupRoleBindings binds missing roles with their items, rebinds managed roles whose items drifted and
+// unbinds managed roles which are no longer desired (every other role in exclusive mode, where every drifted
+// role is rebound too). It returns the added and removed role ids.
+func syncGroupRoleBindings(ctx context.Context, c *client.Client, groupUUID uuid.UUID, desired map[string]map[string]string, managed []string, exclusive bool) ([]string, []string, error) {
+	rolesList, err := c.GetWorkspaceGroupRoleList(ctx, c.WorkspaceUUID, &groupUUID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read group roles: %w", err)
+	}
+	remoteRolesID := make([]string, 0, len(rolesList))
+	remoteItems := make(map[string][]map[string]string, len(rolesList))
+	for _, r := range rolesList {
+		remoteRolesID = append(remoteRolesID, r.Uuid)
+		remoteItems[r.Uuid] = append(remoteItems[r.Uuid], r.Items...)
+	}
+	remoteRolesID = uniqueSorted(remoteRolesID)
+
+	sortedRoleIds := bindingIDs(desired)
+	toAddList, toRemoveList := memberDelta(sortedRoleIds, remoteRolesID, managed, exclusive)
+	toRebindList := driftedBindingIDs(rebindableIDs(setKeys(intersect(toSet(sortedRoleIds), toSet(remoteRolesID))), managed, exclusive), remoteItems, desired)
+
+	toUnbindList := append(append([]string{}, toRebindList...), toRemoveList...)
+	for _, s := range toUnbindList {
+		u, err := uuid.FromString(s)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid role_id in list: %w", err)
+		}
+		if err := c.UnbindRoleFromGroup(ctx, &u, &groupUUID); err != nil {
+			return nil, nil, fmt.Errorf("unbind role %s from group %s failed: %w", u.String(), groupUUID.String(), err)
+		}
+	}
+
+	toBindList := uniqueSorted(append(append([]string{}, toAddList...), toRebindList...))
+	if len(toBindList) > 0 {
+		rolesWithItems := make([]iam.IamRoleItem, 0, len(toBindList))
+		for _, id := range toBindList {
+			items := desired[id]
+			if items == nil {
+				items = map[string]string{}
+			}
+			rolesWithItems = append(rolesWithItems, iam.IamRoleItem{
+				RoleUuid:  id,
+				ItemsList: &[]map[string]string{items}})
+		}
+		if err := c.BulkAddRolesToGroup(ctx, &groupUUID, rolesWithItems); err != nil {
+			return nil, nil, fmt.Errorf("bulk bind roles to group %s failed: %w", groupUUID.String(), err)
+		}
+	}
+
+	return toAddList, toRemoveList, nil
 }
 
 func resourceGroupRoleDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
@@ -161,7 +309,7 @@ func resourceGroupRoleDelete(ctx context.Context, d *schema.ResourceData, meta i
 		return diag.Errorf("invalid group_id %q: %s", groupStr, err)
 	}
 
-	roleIds := d.Get("role_ids").(*schema.Set).List()
+	roleIds := d.Get("managed_role_ids").(*schema.Set).List()
 	for _, v := range roleIds {
 		s := v.(string)
 		u, err := uuid.FromString(s)
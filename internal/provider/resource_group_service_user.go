@@ -6,6 +6,7 @@ import (
 
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	uuid "github.com/satori/go.uuid"
 
@@ -17,7 +18,17 @@ func resourceGroupServiceUser() *schema.Resource {
 		Description:   "Binds service users to a group within a Sotoon workspace.",
 		CreateContext: resourceGroupServiceUserCreate,
 		ReadContext:   resourceGroupServiceUserRead,
+		UpdateContext: resourceGroupServiceUserUpdate,
 		DeleteContext: resourceGroupServiceUserDelete,
+		CustomizeDiff: customdiff.All(
+			customdiff.ComputedIf("bindings_hash", func(ctx context.Context, d *schema.ResourceDiff, meta interface{}) bool {
+				return d.HasChange("service_user_ids")
+			}),
+			customdiff.ComputedIf("managed_service_user_ids", func(ctx context.Context, d *schema.ResourceDiff, meta interface{}) bool {
+				return d.HasChanges("service_user_ids", "exclusive")
+			}),
+			groupLookup.resolveOneDiff("group_name", "group_id"),
+		),
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
@@ -28,19 +39,38 @@ func resourceGroupServiceUser() *schema.Resource {
 				Description: `Composite stable identifier. Does not affect lifecycle.`,
 			},
 			"group_id": {
-				Type:        schema.TypeString,
-				Required:    true,
-				ForceNew:    true,
-				Description: "Group UUID.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ForceNew:     true,
+				ExactlyOneOf: []string{"group_id", "group_name"},
+				Description:  "Group UUID.",
+			},
+			"group_name": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ExactlyOneOf: []string{"group_id", "group_name"},
+				Description:  "Name of the group, resolved to `group_id` at plan time. A name pointing to another group replaces the resource.",
 			},
 			"service_user_ids": {
 				Type:        schema.TypeSet,
 				Required:    true,
 				MinItems:    1,
-				ForceNew:    true,
 				Elem:        &schema.Schema{Type: schema.TypeString},
 				Description: "Set of Service User UUIDs to bind to the group.",
 			},
+			"exclusive": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "If true, `service_user_ids` is authoritative: members added outside of Terraform are reported as drift and removed on apply.",
+			},
+			"managed_service_user_ids": {
+				Type:        schema.TypeSet,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Service user UUIDs this resource added to the group. Only these are removed on destroy; members that existed before are left in place.",
+			},
 			"bindings_hash": {
 				Type:        schema.TypeString,
 				Computed:    true,
@@ -52,6 +82,9 @@ func resourceGroupServiceUser() *schema.Resource {
 
 func resourceGroupServiceUserCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client.Client)
+	if err := groupLookup.resolveOne(ctx, c, d, "group_name", "group_id"); err != nil {
+		return diag.FromErr(err)
+	}
 	groupID := d.Get("group_id").(string)
 	groupUUID, err := uuid.FromString(groupID)
 	if err != nil {
@@ -80,10 +113,25 @@ func resourceGroupServiceUserCreate(ctx context.Context, d *schema.ResourceData,
 		}
 	}
 
+	for _, s := range idsToRemove(nil, sortedServiceUserIds, remoteServiceUsersID, d.Get("exclusive").(bool)) {
+		u, err := uuid.FromString(s)
+		if err != nil {
+			return diag.Errorf("invalid service_user_id in list: %s", err)
+		}
+		if err := c.UnbindServiceUserFromGroup(ctx, &groupUUID, &u); err != nil {
+			return diag.Errorf("unbind service user %s from group %s failed: %s", u.String(), groupUUID.String(), err)
+		}
+	}
+
+	if err := d.Set("managed_service_user_ids", toAddList); err != nil {
+		return diag.FromErr(fmt.Errorf("failed to set managed_service_user_ids: %w", err))
+	}
+
 	bindHash := hashOfIDs(sortedServiceUserIds)
 	if err := d.Set("bindings_hash", bindHash); err != nil {
 		return diag.FromErr(fmt.Errorf("failed to set bindings_hash: %w", err))
 	}
+	// the id is fixed at creation, bindings_hash follows later in-place updates
 	d.SetId(groupUUID.String() + ":" + bindHash)
 
 	return resourceGroupServiceUserRead(ctx, d, meta)
@@ -112,28 +160,83 @@ func resourceGroupServiceUserRead(ctx context.Context, d *schema.ResourceData, m
 	}
 	remoteServiceUsersID = uniqueSorted(remoteServiceUsersID)
 
-	eff := intersect(toSet(sortedServiceUserIds), toSet(remoteServiceUsersID))
-	effective := uniqueSorted(setKeys(eff))
+	effective := effectiveIDs(sortedServiceUserIds, remoteServiceUsersID, d.Get("exclusive").(bool))
 
 	if err := d.Set("service_user_ids", effective); err != nil {
 		return diag.FromErr(fmt.Errorf("failed to set service_user_ids: %w", err))
 	}
 
-	bindHash, _ := d.Get("bindings_hash").(string)
-	if bindHash == "" {
-		bindHash = hashOfIDs(effective)
-		if err := d.Set("bindings_hash", bindHash); err != nil {
+	managed := effective
+	if tracksManagedIDs(d, "managed_service_user_ids") {
+		managed = uniqueSorted(setKeys(intersect(toSet(fromSchemaSetToStrings(d.Get("managed_service_user_ids").(*schema.Set))), toSet(remoteServiceUsersID))))
+	}
+	if err := d.Set("managed_service_user_ids", managed); err != nil {
+		return diag.FromErr(fmt.Errorf("failed to set managed_service_user_ids: %w", err))
+	}
+
+	if d.Get("bindings_hash").(string) == "" {
+		if err := d.Set("bindings_hash", hashOfIDs(effective)); err != nil {
 			return diag.FromErr(fmt.Errorf("failed to set bindings_hash: %w", err))
 		}
 	}
 
-	d.SetId(groupUUID.String() + ":" + bindHash)
-
 	tflog.Info(ctx, "Read service-user group", map[string]interface{}{"group_id": groupID, "have": len(effective)})
 
 	return nil
 }
 
+func resourceGroupServiceUserUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client.Client)
+	groupID := d.Get("group_id").(string)
+	groupUUID, err := uuid.FromString(groupID)
+	if err != nil {
+		return diag.Errorf("invalid group_id: %s", err)
+	}
+
+	if d.HasChanges("service_user_ids", "exclusive") {
+		oldManaged, _ := d.GetChange("managed_service_user_ids")
+		managedIds := fromSchemaSetToStrings(oldManaged.(*schema.Set))
+		sortedServiceUserIds := uniqueSorted(fromSchemaSetToStrings(d.Get("service_user_ids").(*schema.Set)))
+
+		serviceUsersList, err := c.GetAllGroupServiceUserList(ctx, c.WorkspaceUUID, &groupUUID)
+		if err != nil {
+			return diag.Errorf("read group service-users: %s", err)
+		}
+		remoteServiceUsersID := make([]string, 0, len(serviceUsersList))
+		for _, u := range serviceUsersList {
+			remoteServiceUsersID = append(remoteServiceUsersID, u.Uuid)
+		}
+		remoteServiceUsersID = uniqueSorted(remoteServiceUsersID)
+
+		toAddList, toRemoveList := memberDelta(sortedServiceUserIds, remoteServiceUsersID, managedIds, d.Get("exclusive").(bool))
+		if len(toAddList) > 0 {
+			if _, err := c.BulkAddServiceUsersToGroup(ctx, groupUUID, toAddList); err != nil {
+				return diag.Errorf("add service users to group %s: %s", groupID, err)
+			}
+		}
+
+		for _, s := range toRemoveList {
+			u, err := uuid.FromString(s)
+			if err != nil {
+				return diag.Errorf("invalid service_user_id in list: %s", err)
+			}
+			if err := c.UnbindServiceUserFromGroup(ctx, &groupUUID, &u); err != nil {
+				return diag.Errorf("unbind service user %s from group %s failed: %s", u.String(), groupUUID.String(), err)
+			}
+		}
+
+		if err := d.Set("managed_service_user_ids", managedAfter(managedIds, toAddList, toRemoveList)); err != nil {
+			return diag.FromErr(fmt.Errorf("failed to set managed_service_user_ids: %w", err))
+		}
+		if err := d.Set("bindings_hash", hashOfIDs(sortedServiceUserIds)); err != nil {
+			return diag.FromErr(fmt.Errorf("failed to set bindings_hash: %w", err))
+		}
+		tflog.Info(ctx, "Updated service-user group", map[string]interface{}{"group_id": groupID, "added": len(toAddList), "removed": len(toRemoveList)})
+	}
+
+	return resourceGroupServiceUserRead(ctx, d, meta)
+}
+
 func resourceGroupServiceUserDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client.Client)
 	groupID := d.Get("group_id").(string)
@@ -142,7 +245,7 @@ func resourceGroupServiceUserDelete(ctx context.Context, d *schema.ResourceData,
 		return diag.Errorf("invalid group_id %q: %s", groupID, err)
 	}
 
-	serviceUserIds := d.Get("service_user_ids").(*schema.Set).List()
+	serviceUserIds := d.Get("managed_service_user_ids").(*schema.Set).List()
 	for _, v := range serviceUserIds {
 		s := v.(string)
 		u, err := uuid.FromString(s)
@@ -20,6 +20,7 @@ func resourceRole() *schema.Resource {
 		ReadContext:   resourceRoleRead,
 		UpdateContext: resourceRoleUpdate,
 		DeleteContext: resourceRoleDelete,
+		CustomizeDiff: ruleLookup.resolveSetDiff("rule_names", "resolved_rule_ids"),
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
@@ -47,6 +48,27 @@ func resourceRole() *schema.Resource {
 					Type: schema.TypeString,
 				},
 			},
+			"rule_names": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "Names of rules to attach to this role, resolved to UUIDs at plan time. Can be combined with `rules`.",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"scope": nameScopeSchema("rule_names"),
+			"resolved_rule_ids": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Rule UUID of every name in `rule_names`. A name keeps its UUID for as long as it stays in `rule_names`.",
+			},
+			"exclusive_rules": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "If true, `rules` and `rule_names` are authoritative: rules attached outside of Terraform are detached, and leaving both empty detaches all rules.",
+			},
 		},
 	}
 }
@@ -55,9 +77,24 @@ func resourceRoleCreate(ctx context.Context, d *schema.ResourceData, meta interf
 	c := meta.(*client.Client)
 	name := d.Get("name").(string)
 
+	nameRuleIds, err := ruleLookup.resolveSet(ctx, c, d, "rule_names", "resolved_rule_ids")
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	desiredRules := uniqueSorted(append(fromSchemaSetToStrings(d.Get("rules").(*schema.Set)), nameRuleIds...))
+
 	existing, err := c.GetRoleByName(ctx, name)
 	if err == nil {
 		d.SetId(existing.Uuid)
+		if d.Get("exclusive_rules").(bool) {
+			roleUUID, err := uuid.FromString(existing.Uuid)
+			if err != nil {
+				return diag.Errorf("invalid role UUID format: %s", err)
+			}
+			if err := syncRoleRules(ctx, c, roleUUID, desiredRules); err != nil {
+				return diag.Errorf("failed to sync rules of role %q: %s", name, err)
+			}
+		}
 		return resourceRoleRead(ctx, d, meta)
 	}
 
@@ -68,23 +105,14 @@ func resourceRoleCreate(ctx context.Context, d *schema.ResourceData, meta interf
 	d.SetId(created.Uuid)
 
 	// Attach rules if specified
-	if v, ok := d.GetOk("rules"); ok && v.(*schema.Set).Len() > 0 {
+	if len(desiredRules) > 0 {
 		roleUUID, err := uuid.FromString(created.Uuid)
 		if err != nil {
 			return diag.Errorf("invalid role UUID format: %s", err)
 		}
 
-		ruleIDs := v.(*schema.Set).List()
-		ruleUUIDs := make([]string, 0, len(ruleIDs))
-
-		for _, ruleID := range ruleIDs {
-			ruleUUIDs = append(ruleUUIDs, ruleID.(string))
-		}
-
-		if len(ruleUUIDs) > 0 {
-			if err := c.BulkAddRulesToRole(ctx, roleUUID, ruleUUIDs); err != nil {
-				return diag.Errorf("failed to attach rules to role %q: %s", name, err)
-			}
+		if err := c.BulkAddRulesToRole(ctx, roleUUID, desiredRules); err != nil {
+			return diag.Errorf("failed to attach rules to role %q: %s", name, err)
 		}
 	}
 
@@ -123,7 +151,11 @@ func resourceRoleRead(ctx context.Context, d *schema.ResourceData, meta interfac
 		for _, rule := range rules {
 			ruleIDs = append(ruleIDs, rule.Uuid)
 		}
-		if err := d.Set("rules", ruleIDs); err != nil {
+		nameOnlyIds, err := readResolved(d, "rule_names", "resolved_rule_ids", "rules", ruleIDs)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		if err := d.Set("rules", diff(toSet(ruleIDs), toSet(nameOnlyIds))); err != nil {
 			return diag.FromErr(fmt.Errorf("failed to set rules: %w", err))
 		}
 	}
@@ -144,11 +176,29 @@ func resourceRoleUpdate(ctx context.Context, d *schema.ResourceData, meta interf
 		return diag.Errorf("name of role cannot be edited")
 	}
 
+	oldResolved, _ := d.GetChange("resolved_rule_ids")
+	oldNames, _ := d.GetChange("rule_names")
+	oldNameRuleIds := resolvedIDs(fromSchemaSetToStrings(oldNames.(*schema.Set)), stringMap(oldResolved.(map[string]interface{})))
+	nameRuleIds, err := ruleLookup.resolveSet(ctx, c, d, "rule_names", "resolved_rule_ids")
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if d.Get("exclusive_rules").(bool) {
+		if d.HasChanges("rules", "rule_names", "scope", "exclusive_rules") {
+			desiredRules := uniqueSorted(append(fromSchemaSetToStrings(d.Get("rules").(*schema.Set)), nameRuleIds...))
+			if err := syncRoleRules(ctx, c, roleUUID, desiredRules); err != nil {
+				return diag.Errorf("failed to sync rules of role %q: %s", id, err)
+			}
+		}
+		return resourceRoleRead(ctx, d, meta)
+	}
+
 	// Handle rule changes if the rules field has been changed
-	if d.HasChange("rules") {
+	if d.HasChanges("rules", "rule_names", "scope") {
 		old, new := d.GetChange("rules")
-		oldSet := old.(*schema.Set)
-		newSet := new.(*schema.Set)
+		oldSet := schema.NewSet(schema.HashString, append(old.(*schema.Set).List(), toInterfaces(oldNameRuleIds)...))
+		newSet := schema.NewSet(schema.HashString, append(new.(*schema.Set).List(), toInterfaces(nameRuleIds)...))
 
 		if newSet.Len() == 0 {
 			// to prevent detach all rules by setting empty list as the "rules" is an optional field
@@ -205,3 +255,46 @@ func resourceRoleDelete(ctx context.Context, d *schema.ResourceData, meta interf
 	d.SetId("")
 	return nil
 }
+
+// syncRoleRules makes the rules attached to the role exactly match the desired rule UUIDs.
+func syncRoleRules(ctx context.Context, c *client.Client, roleUUID uuid.UUID, desired []string) error {
+	rules, err := c.GetRoleRules(ctx, &roleUUID)
+	if err != nil {
+		return fmt.Errorf("failed to load rules: %w", err)
+	}
+	remote := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		remote = append(remote, rule.Uuid)
+	}
+
+	toAdd, toRemove := ruleChanges(desired, remote)
+	if len(toAdd) > 0 {
+		if err := c.BulkAddRulesToRole(ctx, roleUUID, toAdd); err != nil {
+			return fmt.Errorf("failed to attach rules: %w", err)
+		}
+	}
+
+	for _, ruleID := range toRemove {
+		ruleUUID, err := uuid.FromString(ruleID)
+		if err != nil {
+			return fmt.Errorf("invalid rule UUID format for rule %s: %w", ruleID, err)
+		}
+		if err := c.UnbindRuleFromRole(ctx, &roleUUID, &ruleUUID); err != nil {
+			return fmt.Errorf("failed to detach rule %s: %w", ruleID, err)
+		}
+	}
+	return nil
+}
+
+// rules to attach and to detach so a role holds exactly the desired rules, both sorted
+func ruleChanges(desired, remote []string) (toAdd, toRemove []string) {
+	return diff(toSet(desired), toSet(remote)), diff(toSet(remote), toSet(desired))
+}
+
+func toInterfaces(in []string) []interface{} {
+	out := make([]interface{}, 0, len(in))
+	for _, v := range in {
+		out = append(out, v)
+	}
+	return out
+}
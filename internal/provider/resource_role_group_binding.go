@@ -0,0 +1,143 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	uuid "github.com/satori/go.uuid"
+	iam "github.com/sotoon/sotoon-sdk-go/sdk/core/iam_v1"
+	"github.com/sotoon/terraform-provider-sotoon/internal/client"
+)
+
+func resourceRoleGroupBinding() *schema.Resource {
+	return &schema.Resource{
+		Description:   "Binds a single group to an IAM role within a Sotoon workspace.",
+		CreateContext: resourceRoleGroupBindingCreate,
+		ReadContext:   resourceRoleGroupBindingRead,
+		DeleteContext: resourceRoleGroupBindingDelete,
+		CustomizeDiff: validateBindingItems("role_id", "", ""),
+		Importer: &schema.ResourceImporter{
+			StateContext: importTwoPartID("role_id", "group_id"),
+		},
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Identifier in the form `<role_id>/<group_id>`.",
+			},
+			"role_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Role UUID.",
+			},
+			"group_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Group UUID to bind to the role.",
+			},
+			"items": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				ForceNew:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Optional key/value items for this binding.",
+			},
+		},
+	}
+}
+
+func resourceRoleGroupBindingCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client.Client)
+
+	roleUUID, err := uuid.FromString(d.Get("role_id").(string))
+	if err != nil {
+		return diag.Errorf("invalid role_id: %s", err)
+	}
+	groupUUID, err := uuid.FromString(d.Get("group_id").(string))
+	if err != nil {
+		return diag.Errorf("invalid group_id: %s", err)
+	}
+
+	items := map[string]string{}
+	if raw, ok := d.GetOk("items"); ok && raw != nil {
+		for k, v := range raw.(map[string]interface{}) {
+			items[k] = fmt.Sprintf("%v", v)
+		}
+	}
+	roleWithItems := []iam.IamRoleItem{{
+		RoleUuid:  roleUUID.String(),
+		ItemsList: &[]map[string]string{items},
+	}}
+	if err := c.BulkAddRolesToGroup(ctx, &groupUUID, roleWithItems); err != nil {
+		return diag.Errorf("bind role %s to group %s failed: %s", roleUUID, groupUUID, err)
+	}
+
+	d.SetId(fmt.Sprintf("%s/%s", roleUUID.String(), groupUUID.String()))
+	return resourceRoleGroupBindingRead(ctx, d, meta)
+}
+
+func resourceRoleGroupBindingRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client.Client)
+	roleUUID, groupUUID, err := parseTwoPartID(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	rolesList, err := c.GetWorkspaceGroupRoleList(ctx, c.WorkspaceUUID, &groupUUID)
+	if err != nil {
+		if errors.Is(err, client.ErrNotFound) {
+			d.SetId("")
+			return nil
+		}
+		return diag.Errorf("read group roles: %s", err)
+	}
+
+	// the roles of a group carry the items of the group's binding
+	bound := false
+	var remoteItems []map[string]string
+	for _, r := range rolesList {
+		if r.Uuid == roleUUID.String() {
+			bound = true
+			remoteItems = append(remoteItems, r.Items...)
+		}
+	}
+	if bound {
+		items, err := bindingItems(remoteItems)
+		if err != nil {
+			return diag.Errorf("group %s is %s", groupUUID, err)
+		}
+		if err := d.Set("role_id", roleUUID.String()); err != nil {
+			return diag.FromErr(fmt.Errorf("failed to set role_id: %w", err))
+		}
+		if err := d.Set("group_id", groupUUID.String()); err != nil {
+			return diag.FromErr(fmt.Errorf("failed to set group_id: %w", err))
+		}
+		if err := d.Set("items", items); err != nil {
+			return diag.FromErr(fmt.Errorf("failed to set items: %w", err))
+		}
+		return nil
+	}
+
+	tflog.Info(ctx, "Group is no longer bound to the role", map[string]interface{}{"id": d.Id()})
+	d.SetId("")
+	return nil
+}
+
+func resourceRoleGroupBindingDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client.Client)
+	roleUUID, groupUUID, err := parseTwoPartID(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if err := c.UnbindRoleFromGroup(ctx, &roleUUID, &groupUUID); err != nil {
+		return diag.Errorf("unbind role %s from group %s failed: %s", roleUUID, groupUUID, err)
+	}
+	d.SetId("")
+	return nil
+}
@@ -0,0 +1,130 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	uuid "github.com/satori/go.uuid"
+	"github.com/sotoon/terraform-provider-sotoon/internal/client"
+)
+
+func resourceRoleServiceUserBinding() *schema.Resource {
+	return &schema.Resource{
+		Description:   "Binds a single service user to an IAM role within a Sotoon workspace.",
+		CreateContext: resourceRoleServiceUserBindingCreate,
+		ReadContext:   resourceRoleServiceUserBindingRead,
+		DeleteContext: resourceRoleServiceUserBindingDelete,
+		CustomizeDiff: validateBindingItems("role_id", "", ""),
+		Importer: &schema.ResourceImporter{
+			StateContext: importTwoPartID("role_id", "service_user_id"),
+		},
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Identifier in the form `<role_id>/<service_user_id>`.",
+			},
+			"role_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Role UUID.",
+			},
+			"service_user_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Service user UUID to bind to the role.",
+			},
+			"items": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				ForceNew:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Optional key/value items for this binding.",
+			},
+		},
+	}
+}
+
+func resourceRoleServiceUserBindingCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client.Client)
+
+	roleUUID, err := uuid.FromString(d.Get("role_id").(string))
+	if err != nil {
+		return diag.Errorf("invalid role_id: %s", err)
+	}
+	serviceUserUUID, err := uuid.FromString(d.Get("service_user_id").(string))
+	if err != nil {
+		return diag.Errorf("invalid service_user_id: %s", err)
+	}
+
+	var itemsToAdd map[string]interface{}
+	if items, found := d.GetOk("items"); found {
+		itemsToAdd = items.(map[string]interface{})
+	}
+	if err := c.BulkAddServiceUsersToRole(ctx, roleUUID, []string{serviceUserUUID.String()}, itemsToAdd); err != nil {
+		return diag.Errorf("bind service user %s to role %s: %s", serviceUserUUID, roleUUID, err)
+	}
+
+	d.SetId(fmt.Sprintf("%s/%s", roleUUID.String(), serviceUserUUID.String()))
+	return resourceRoleServiceUserBindingRead(ctx, d, meta)
+}
+
+func resourceRoleServiceUserBindingRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client.Client)
+	roleUUID, serviceUserUUID, err := parseTwoPartID(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	serviceUsersList, err := c.GetRoleServiceUsers(ctx, &roleUUID)
+	if err != nil {
+		if errors.Is(err, client.ErrNotFound) {
+			d.SetId("")
+			return nil
+		}
+		return diag.Errorf("read service-users of role: %s", err)
+	}
+
+	for _, su := range serviceUsersList {
+		if su.Uuid != serviceUserUUID.String() {
+			continue
+		}
+		if err := d.Set("role_id", roleUUID.String()); err != nil {
+			return diag.FromErr(fmt.Errorf("failed to set role_id: %w", err))
+		}
+		if err := d.Set("service_user_id", serviceUserUUID.String()); err != nil {
+			return diag.FromErr(fmt.Errorf("failed to set service_user_id: %w", err))
+		}
+		items, err := bindingItems(su.Items)
+		if err != nil {
+			return diag.Errorf("service user %s is %s", su.Uuid, err)
+		}
+		if err := d.Set("items", items); err != nil {
+			return diag.FromErr(fmt.Errorf("failed to set items: %w", err))
+		}
+		return nil
+	}
+
+	tflog.Info(ctx, "Service user is no longer bound to the role", map[string]interface{}{"id": d.Id()})
+	d.SetId("")
+	return nil
+}
+
+func resourceRoleServiceUserBindingDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client.Client)
+	roleUUID, serviceUserUUID, err := parseTwoPartID(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if err := c.UnbindRoleFromServiceUser(ctx, &roleUUID, &serviceUserUUID); err != nil {
+		return diag.Errorf("unbind service user %s from role %s failed: %s", serviceUserUUID, roleUUID, err)
+	}
+	d.SetId("")
+	return nil
+}
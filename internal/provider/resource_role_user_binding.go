@@ -0,0 +1,130 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	uuid "github.com/satori/go.uuid"
+	"github.com/sotoon/terraform-provider-sotoon/internal/client"
+)
+
+func resourceRoleUserBinding() *schema.Resource {
+	return &schema.Resource{
+		Description:   "Binds a single user to an IAM role within a Sotoon workspace.",
+		CreateContext: resourceRoleUserBindingCreate,
+		ReadContext:   resourceRoleUserBindingRead,
+		DeleteContext: resourceRoleUserBindingDelete,
+		CustomizeDiff: validateBindingItems("role_id", "", ""),
+		Importer: &schema.ResourceImporter{
+			StateContext: importTwoPartID("role_id", "user_id"),
+		},
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Identifier in the form `<role_id>/<user_id>`.",
+			},
+			"role_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Role UUID.",
+			},
+			"user_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "User UUID to bind to the role.",
+			},
+			"items": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				ForceNew:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Optional key/value items for this binding.",
+			},
+		},
+	}
+}
+
+func resourceRoleUserBindingCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client.Client)
+
+	roleUUID, err := uuid.FromString(d.Get("role_id").(string))
+	if err != nil {
+		return diag.Errorf("invalid role_id: %s", err)
+	}
+	userUUID, err := uuid.FromString(d.Get("user_id").(string))
+	if err != nil {
+		return diag.Errorf("invalid user_id: %s", err)
+	}
+
+	var itemsToAdd map[string]interface{}
+	if items, found := d.GetOk("items"); found {
+		itemsToAdd = items.(map[string]interface{})
+	}
+	if err := c.BulkAddUsersToRole(ctx, roleUUID, []string{userUUID.String()}, itemsToAdd); err != nil {
+		return diag.Errorf("bind user %s to role %s: %s", userUUID, roleUUID, err)
+	}
+
+	d.SetId(fmt.Sprintf("%s/%s", roleUUID.String(), userUUID.String()))
+	return resourceRoleUserBindingRead(ctx, d, meta)
+}
+
+func resourceRoleUserBindingRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client.Client)
+	roleUUID, userUUID, err := parseTwoPartID(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	usersList, err := c.GetRoleUsers(ctx, &roleUUID)
+	if err != nil {
+		if errors.Is(err, client.ErrNotFound) {
+			d.SetId("")
+			return nil
+		}
+		return diag.Errorf("read users of role: %s", err)
+	}
+
+	for _, u := range usersList {
+		if u.Uuid != userUUID.String() {
+			continue
+		}
+		if err := d.Set("role_id", roleUUID.String()); err != nil {
+			return diag.FromErr(fmt.Errorf("failed to set role_id: %w", err))
+		}
+		if err := d.Set("user_id", userUUID.String()); err != nil {
+			return diag.FromErr(fmt.Errorf("failed to set user_id: %w", err))
+		}
+		items, err := bindingItems(u.Items)
+		if err != nil {
+			return diag.Errorf("user %s is %s", u.Uuid, err)
+		}
+		if err := d.Set("items", items); err != nil {
+			return diag.FromErr(fmt.Errorf("failed to set items: %w", err))
+		}
+		return nil
+	}
+
+	tflog.Info(ctx, "User is no longer bound to the role", map[string]interface{}{"id": d.Id()})
+	d.SetId("")
+	return nil
+}
+
+func resourceRoleUserBindingDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client.Client)
+	roleUUID, userUUID, err := parseTwoPartID(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if err := c.UnbindRoleFromUser(ctx, &roleUUID, &userUUID); err != nil {
+		return diag.Errorf("unbind user %s from role %s failed: %s", userUUID, roleUUID, err)
+	}
+	d.SetId("")
+	return nil
+}
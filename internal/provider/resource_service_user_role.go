@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	uuid "github.com/satori/go.uuid"
 
@@ -16,7 +18,18 @@ func resourceServiceUserRole() *schema.Resource {
 		Description:   "Manages a role for a service user within a Sotoon workspace.",
 		CreateContext: resourceServiceUserRoleCreate,
 		ReadContext:   resourceServiceUserRoleRead,
+		UpdateContext: resourceServiceUserRoleUpdate,
 		DeleteContext: resourceServiceUserRoleDelete,
+		CustomizeDiff: customdiff.All(
+			customdiff.ComputedIf("bindings_hash", func(ctx context.Context, d *schema.ResourceDiff, meta interface{}) bool {
+				return d.HasChanges("service_user_ids", "binding")
+			}),
+			customdiff.ComputedIf("managed_service_user_ids", func(ctx context.Context, d *schema.ResourceDiff, meta interface{}) bool {
+				return d.HasChanges("service_user_ids", "binding", "exclusive")
+			}),
+			roleLookup.resolveOneDiff("role_name", "role_id"),
+			validateBindingItems("role_id", "service_user_ids", ""),
+		),
 		Schema: map[string]*schema.Schema{
 			"id": {
 				Type:        schema.TypeString,
@@ -24,26 +37,48 @@ func resourceServiceUserRole() *schema.Resource {
 				Description: `Composite stable identifier. Does not affect lifecycle.`,
 			},
 			"service_user_ids": {
-				Type:        schema.TypeSet,
-				Required:    true,
-				MinItems:    1,
-				ForceNew:    true,
-				Description: "List of service user UUIDs to bind to the role.",
+				Type:         schema.TypeSet,
+				Optional:     true,
+				MinItems:     1,
+				ExactlyOneOf: []string{"service_user_ids", "binding"},
+				Description:  "List of service user UUIDs to bind to the role.",
 				Elem: &schema.Schema{
 					Type: schema.TypeString,
 				},
 			},
+			"binding": roleBindingSchema("Service user UUID to bind to the role.", "service_user_ids"),
 			"role_id": {
-				Type:        schema.TypeString,
-				Required:    true,
-				ForceNew:    true,
-				Description: "Role UUID.",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ForceNew:     true,
+				ExactlyOneOf: []string{"role_id", "role_name"},
+				Description:  "Role UUID.",
+			},
+			"role_name": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ExactlyOneOf: []string{"role_id", "role_name"},
+				Description:  "Name of the role, resolved to `role_id` at plan time. A name pointing to another role replaces the resource.",
 			},
+			"scope": nameScopeSchema("role_name"),
 			"items": {
-				Type:        schema.TypeMap,
+				Type:          schema.TypeMap,
+				Optional:      true,
+				ConflictsWith: []string{"binding"},
+				Description:   "map of items related to this role. Items are read back for every bound service user; a difference shows up as drift and the affected service users are rebound in place.",
+			},
+			"exclusive": {
+				Type:        schema.TypeBool,
 				Optional:    true,
-				ForceNew:    true,
-				Description: "map of items related to this role.",
+				Default:     false,
+				Description: "If true, `service_user_ids` is authoritative: bindings added outside of Terraform are reported as drift and removed on apply.",
+			},
+			"managed_service_user_ids": {
+				Type:        schema.TypeSet,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Service user UUIDs this resource bound to the role. Only these are unbound on destroy; bindings that existed before are left in place.",
 			},
 			"bindings_hash": {
 				Type:        schema.TypeString,
@@ -56,42 +91,33 @@ func resourceServiceUserRole() *schema.Resource {
 
 func resourceServiceUserRoleCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client.Client)
+	if err := roleLookup.resolveOne(ctx, c, d, "role_name", "role_id"); err != nil {
+		return diag.FromErr(err)
+	}
+
 	roleID := d.Get("role_id").(string)
 	roleUUID, err := uuid.FromString(roleID)
 	if err != nil {
 		return diag.Errorf("invalid role_id: %s", err)
 	}
 
-	sortedServiceUserIds := uniqueSorted(fromSchemaSetToStrings(d.Get("service_user_ids").(*schema.Set)))
-
-	serviceUsersList, err := c.GetRoleServiceUsers(ctx, &roleUUID)
+	desired := desiredBindings(d, "service_user_ids")
+	toAddList, _, err := syncServiceUserRoleBindings(ctx, c, roleUUID, desired, nil, d.Get("exclusive").(bool))
 	if err != nil {
-		return diag.Errorf("read service-users of role: %s", err)
+		return diag.FromErr(err)
 	}
 
-	remoteServiceUsersID := make([]string, 0, len(serviceUsersList))
-	for _, u := range serviceUsersList {
-		remoteServiceUsersID = append(remoteServiceUsersID, u.Uuid)
+	if err := d.Set("managed_service_user_ids", toAddList); err != nil {
+		return diag.FromErr(fmt.Errorf("failed to set managed_service_user_ids: %w", err))
 	}
 
-	remoteServiceUsersID = uniqueSorted(remoteServiceUsersID)
-	toAddList := diff(toSet(sortedServiceUserIds), toSet(remoteServiceUsersID))
-	if len(toAddList) > 0 {
-		var itemsToAdd map[string]interface{}
-		if items, found := d.GetOk("items"); found {
-			itemsToAdd = items.(map[string]interface{})
-		}
-		if err := c.BulkAddServiceUsersToRole(ctx, roleUUID, toAddList, itemsToAdd); err != nil {
-			return diag.Errorf("add service users to role %s: %s", roleUUID, err)
-		}
-	}
+	bindHash := hashOfIDs(bindingIDs(desired))
 
-	bindHash := hashOfIDs(sortedServiceUserIds)
 	if err := d.Set("bindings_hash", bindHash); err != nil {
 		return diag.FromErr(fmt.Errorf("failed to set bindings_hash: %w", err))
 	}
+	// the id is fixed at creation, bindings_hash follows later in-place updates
 	d.SetId(roleUUID.String() + ":" + bindHash)
-
 	return resourceServiceUserRoleRead(ctx, d, meta)
 }
 
@@ -105,7 +131,8 @@ func resourceServiceUserRoleRead(ctx context.Context, d *schema.ResourceData, me
 		return nil
 	}
 
-	sortedServiceUserIds := uniqueSorted(fromSchemaSetToStrings(d.Get("service_user_ids").(*schema.Set)))
+	desired := desiredBindings(d, "service_user_ids")
+	sortedServiceUserIds := bindingIDs(desired)
 
 	serviceUsersList, err := c.GetRoleServiceUsers(ctx, &roleUUID)
 	if err != nil {
@@ -113,28 +140,131 @@ func resourceServiceUserRoleRead(ctx context.Context, d *schema.ResourceData, me
 	}
 
 	remoteServiceUsersID := make([]string, 0, len(serviceUsersList))
+	remoteItems := make(map[string][]map[string]string, len(serviceUsersList))
 	for _, u := range serviceUsersList {
 		remoteServiceUsersID = append(remoteServiceUsersID, u.Uuid)
+		remoteItems[u.Uuid] = u.Items
 	}
 	remoteServiceUsersID = uniqueSorted(remoteServiceUsersID)
 
-	eff := intersect(toSet(sortedServiceUserIds), toSet(remoteServiceUsersID))
-	effective := uniqueSorted(setKeys(eff))
+	effective := effectiveIDs(sortedServiceUserIds, remoteServiceUsersID, d.Get("exclusive").(bool))
+
+	managed := effective
+	if tracksManagedIDs(d, "managed_service_user_ids") {
+		managed = uniqueSorted(setKeys(intersect(toSet(fromSchemaSetToStrings(d.Get("managed_service_user_ids").(*schema.Set))), toSet(remoteServiceUsersID))))
+	}
+	// only members this resource bound, or every member in exclusive mode, are rebound on drift
+	rebindable := rebindableIDs(effective, managed, d.Get("exclusive").(bool))
+
+	var diags diag.Diagnostics
+	if _, ok := d.GetOk("binding"); ok {
+		bindings, err := flattenBindings(effective, rebindable, desired, remoteItems)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		if err := d.Set("binding", bindings); err != nil {
+			return diag.FromErr(fmt.Errorf("failed to set binding: %w", err))
+		}
+	} else {
+		if err := d.Set("service_user_ids", effective); err != nil {
+			return diag.FromErr(fmt.Errorf("failed to set service_user_ids: %w", err))
+		}
+
+		if drifted := driftedItemIDs(rebindable, remoteItems, stringMap(d.Get("items").(map[string]interface{}))); len(drifted) > 0 {
+			var items map[string]string
+			items, diags = driftedSharedItems(drifted, remoteItems)
+			if diags.HasError() {
+				return diags
+			}
+			if err := d.Set("items", items); err != nil {
+				return diag.FromErr(fmt.Errorf("failed to set items: %w", err))
+			}
+		}
+	}
 
-	if err := d.Set("service_user_ids", effective); err != nil {
-		return diag.FromErr(fmt.Errorf("failed to set service_user_ids: %w", err))
+	if err := d.Set("managed_service_user_ids", managed); err != nil {
+		return diag.FromErr(fmt.Errorf("failed to set managed_service_user_ids: %w", err))
 	}
 
-	bindHash, _ := d.Get("bindings_hash").(string)
-	if bindHash == "" {
-		bindHash = hashOfIDs(effective)
-		if err := d.Set("bindings_hash", bindHash); err != nil {
+	if d.Get("bindings_hash").(string) == "" {
+		if err := d.Set("bindings_hash", hashOfIDs(effective)); err != nil {
 			return diag.FromErr(fmt.Errorf("failed to set bindings_hash: %w", err))
 		}
 	}
 
-	d.SetId(roleUUID.String() + ":" + bindHash)
-	return nil
+	return diags
+}
+
+func resourceServiceUserRoleUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client.Client)
+
+	roleID := d.Get("role_id").(string)
+	roleUUID, err := uuid.FromString(roleID)
+	if err != nil {
+		return diag.Errorf("invalid role_id: %s", err)
+	}
+
+	if d.HasChanges("service_user_ids", "binding", "items", "exclusive") {
+		oldManaged, _ := d.GetChange("managed_service_user_ids")
+		managedIds := fromSchemaSetToStrings(oldManaged.(*schema.Set))
+		desired := desiredBindings(d, "service_user_ids")
+
+		toAddList, toRemoveList, err := syncServiceUserRoleBindings(ctx, c, roleUUID, desired, managedIds, d.Get("exclusive").(bool))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+
+		if err := d.Set("managed_service_user_ids", managedAfter(managedIds, toAddList, toRemoveList)); err != nil {
+			return diag.FromErr(fmt.Errorf("failed to set managed_service_user_ids: %w", err))
+		}
+		if err := d.Set("bindings_hash", hashOfIDs(bindingIDs(desired))); err != nil {
+			return diag.FromErr(fmt.Errorf("failed to set bindings_hash: %w", err))
+		}
+		tflog.Info(ctx, "Updated service-user role", map[string]interface{}{"role_id": roleID, "added": len(toAddList), "removed": len(toRemoveList)})
+	}
+
+	return resourceServiceUserRoleRead(ctx, d, meta)
+}
+
+// syncServiceUserRoleBindings binds missing service users with their items, rebinds managed service users
+// whose items drifted and unbinds managed service users which are no longer desired (every other one in
+// exclusive mode, where every drifted one is rebound too). It returns the added and removed service user ids.
+func syncServiceUserRoleBindings(ctx context.Context, c *client.Client, roleUUID uuid.UUID, desired map[string]map[string]string, managed []string, exclusive bool) ([]string, []string, error) {
+	serviceUsersList, err := c.GetRoleServiceUsers(ctx, &roleUUID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read service-users of role: %w", err)
+	}
+	remoteServiceUsersID := make([]string, 0, len(serviceUsersList))
+	remoteItems := make(map[string][]map[string]string, len(serviceUsersList))
+	for _, u := range serviceUsersList {
+		remoteServiceUsersID = append(remoteServiceUsersID, u.Uuid)
+		remoteItems[u.Uuid] = u.Items
+	}
+	remoteServiceUsersID = uniqueSorted(remoteServiceUsersID)
+
+	sortedServiceUserIds := bindingIDs(desired)
+	toAddList, toRemoveList := memberDelta(sortedServiceUserIds, remoteServiceUsersID, managed, exclusive)
+	toRebindList := driftedBindingIDs(rebindableIDs(setKeys(intersect(toSet(sortedServiceUserIds), toSet(remoteServiceUsersID))), managed, exclusive), remoteItems, desired)
+
+	toUnbindList := append(append([]string{}, toRebindList...), toRemoveList...)
+	for _, v := range toUnbindList {
+		u, err := uuid.FromString(v)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid service_user_id in list: %w", err)
+		}
+		if err := c.UnbindRoleFromServiceUser(ctx, &roleUUID, &u); err != nil {
+			return nil, nil, fmt.Errorf("unbind service user %s from role %s failed: %w", u.String(), roleUUID.String(), err)
+		}
+	}
+
+	toBindList := uniqueSorted(append(append([]string{}, toAddList...), toRebindList...))
+	if len(toBindList) > 0 {
+		if err := c.BulkAddServiceUsersToRoleWithItems(ctx, roleUUID, toBindList, bindingItemsList(toBindList, desired)); err != nil {
+			return nil, nil, fmt.Errorf("add service users to role %s: %w", roleUUID, err)
+		}
+	}
+
+	return toAddList, toRemoveList, nil
 }
 
 func resourceServiceUserRoleDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
@@ -146,7 +276,7 @@ func resourceServiceUserRoleDelete(ctx context.Context, d *schema.ResourceData,
 		return diag.Errorf("invalid role_id %q: %s", roleID, err)
 	}
 
-	raw := d.Get("service_user_ids").(*schema.Set).List()
+	raw := d.Get("managed_service_user_ids").(*schema.Set).List()
 	for _, v := range raw {
 		u, err := uuid.FromString(v.(string))
 		if err != nil {
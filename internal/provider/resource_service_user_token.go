@@ -5,19 +5,30 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 
 	uuid "github.com/satori/go.uuid"
+	iam "github.com/sotoon/sotoon-sdk-go/sdk/core/iam_v1"
 	"github.com/sotoon/terraform-provider-sotoon/internal/client"
 )
 
 func resourceServiceUserToken() *schema.Resource {
 	return &schema.Resource{
-		Description:   "Manages a token for a service user within a Sotoon workspace.",
+		Description: "Manages a token for a service user within a Sotoon workspace. " +
+			"With a `rotation` block, a new token is issued on the first apply after `rotate_after` has passed, " +
+			"and the previous token is kept for `overlap` before it is deleted. " +
+			"With `renew_before`, the token is replaced once it is that close to its expiry.",
 		CreateContext: resourceServiceUserTokenCreate,
 		ReadContext:   resourceServiceUserTokenRead,
+		UpdateContext: resourceServiceUserTokenUpdate,
 		DeleteContext: resourceServiceUserTokenDelete,
+		CustomizeDiff: customdiff.All(
+			resourceServiceUserTokenCustomizeDiff,
+			renewTokenDiff("expires_at"),
+		),
 		Schema: map[string]*schema.Schema{
 			"id": {
 				Type:        schema.TypeString,
@@ -31,10 +42,28 @@ func resourceServiceUserToken() *schema.Resource {
 				Description: "Name of the token.",
 			},
 			"expires_at": {
-				Type:        schema.TypeString,
-				Optional:    true,
-				ForceNew:    true,
-				Description: "Expiration time of the token in RFC3339 format.",
+				Type:             schema.TypeString,
+				Optional:         true,
+				Computed:         true,
+				ValidateFunc:     validateExpiry,
+				DiffSuppressFunc: suppressEquivalentExpiry,
+				ConflictsWith:    []string{"expires_in"},
+				Description:      "Expiration time of the token in RFC3339 format, or a date such as `2025-09-30` for midnight UTC. Changing it replaces the token. Set from `expires_in` when that is used, and renewed by a rotation.",
+			},
+			"expires_in": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ForceNew:      true,
+				ValidateFunc:  validateLongDuration,
+				ConflictsWith: []string{"expires_at"},
+				Description:   "Lifetime of the token from its creation, as a duration such as `90d` or `36h`. Changing it replaces the token.",
+			},
+			"renew_before": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateLongDuration,
+				RequiredWith: []string{"expires_in"},
+				Description:  "Replace the token on the first plan within this duration of its expiry, such as `7d`. Requires `expires_in`, so the new token gets a fresh expiry.",
 			},
 			"service_user_id": {
 				Type:        schema.TypeString,
@@ -42,16 +71,127 @@ func resourceServiceUserToken() *schema.Resource {
 				ForceNew:    true,
 				Description: "Service User UUID.",
 			},
+			"rotation": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: "Rotates the token on a schedule. Rotation happens during apply, so it is only as timely as your applies.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"rotate_after": {
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validateDuration,
+							Description:  "Age of the current token after which a new one is issued, as a duration such as `720h`.",
+						},
+						"overlap": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      "24h",
+							ValidateFunc: validateDuration,
+							Description:  "How long the previous token stays valid after a rotation. `0s` deletes it immediately.",
+						},
+					},
+				},
+			},
 			"value": {
 				Type:        schema.TypeString,
 				Computed:    true,
 				Sensitive:   true,
 				Description: "The newly issued service user token value",
 			},
+			"current_value": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Sensitive:   true,
+				Description: "Value of the current token, the same as `value`.",
+			},
+			"token_id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "UUID of the current token. Changes on every rotation.",
+			},
+			"created_at": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Time the current token was issued (RFC3339).",
+			},
+			"rotate_at": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Time after which the next apply rotates the token (RFC3339). Empty without a `rotation` block.",
+			},
+			"previous_token_id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "UUID of the previous token while it is kept for the overlap window.",
+			},
+			"previous_value": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Sensitive:   true,
+				Description: "Value of the previous token while it is kept for the overlap window.",
+			},
+			"previous_retire_at": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Time after which the next apply deletes the previous token (RFC3339).",
+			},
 		},
 	}
 }
 
+// resourceServiceUserTokenCustomizeDiff plans a rotation once the current token is older than rotate_after,
+// and the deletion of the previous token once its overlap window has passed.
+func resourceServiceUserTokenCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if d.Id() == "" {
+		return nil
+	}
+	// expires_at is not ForceNew in the schema so a rotation can renew it in place, a configured change
+	// still replaces the token
+	if d.HasChange("expires_at") {
+		return d.ForceNew("expires_at")
+	}
+	rotateAfter, _, ok, err := tokenRotation(d.Get("rotation").([]interface{}))
+	if err != nil {
+		return err
+	}
+	createdAt := parseStateTime(d.Get("created_at").(string))
+	rotate, retire := rotationDue(createdAt, rotateAfter, parseStateTime(d.Get("previous_retire_at").(string)), time.Now())
+
+	if rotate {
+		keys := []string{"token_id", "value", "current_value", "created_at", "rotate_at"}
+		// the new token gets a fresh expiry from expires_in
+		if _, ok := d.GetOk("expires_in"); ok {
+			keys = append(keys, "expires_at")
+		}
+		for _, key := range keys {
+			if err := d.SetNewComputed(key); err != nil {
+				return err
+			}
+		}
+	}
+	if rotate || retire {
+		for _, key := range []string{"previous_token_id", "previous_value", "previous_retire_at"} {
+			if err := d.SetNewComputed(key); err != nil {
+				return err
+			}
+		}
+	}
+	if rotate {
+		return nil
+	}
+
+	rotateAt := ""
+	if ok && !createdAt.IsZero() {
+		rotateAt = createdAt.Add(rotateAfter).UTC().Format(time.RFC3339)
+	}
+	if rotateAt != d.Get("rotate_at").(string) {
+		return d.SetNew("rotate_at", rotateAt)
+	}
+	return nil
+}
+
 func resourceServiceUserTokenCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client.Client)
 
@@ -61,27 +201,15 @@ func resourceServiceUserTokenCreate(ctx context.Context, d *schema.ResourceData,
 		return diag.FromErr(err)
 	}
 
-	name := d.Get("name").(string)
-
-	var expiresAt *time.Time
-	if expiresAtString, ok := d.GetOk("expires_at"); ok {
-		expAt, err := time.Parse(time.RFC3339, expiresAtString.(string))
-		if err != nil {
-			return diag.FromErr(err)
-		}
-		expiresAt = &expAt
-	}
-
-	tok, err := c.CreateServiceUserToken(ctx, &serviceUserUUID, name, expiresAt)
+	tok, err := createServiceUserToken(ctx, c, d, &serviceUserUUID)
 	if err != nil {
 		return diag.FromErr(err)
 	}
-	if tok.Secret == nil {
-		return diag.Errorf("empty token response")
+	if err := setCurrentServiceUserToken(d, tok); err != nil {
+		return diag.FromErr(err)
 	}
-
-	if err := d.Set("value", *tok.Secret); err != nil {
-		return diag.Errorf("error setting token value: %s", err)
+	if err := setPreviousServiceUserToken(d, "", "", ""); err != nil {
+		return diag.FromErr(err)
 	}
 
 	d.SetId(fmt.Sprintf("%s/%s", serviceUserUUID.String(), *tok.Uuid))
@@ -95,6 +223,12 @@ func resourceServiceUserTokenRead(ctx context.Context, d *schema.ResourceData, m
 	if err != nil {
 		return diag.FromErr(err)
 	}
+	// the id keeps the first token, later rotations only change token_id
+	if current, ok := d.GetOk("token_id"); ok {
+		if tokenID, err = uuid.FromString(current.(string)); err != nil {
+			return diag.Errorf("invalid token_id %q: %s", current, err)
+		}
+	}
 
 	list, err := c.GetWorkspaceServiceUserTokenList(ctx, &serviceUserID, c.WorkspaceUUID)
 	if err != nil && err != client.ErrNotFound {
@@ -102,6 +236,7 @@ func resourceServiceUserTokenRead(ctx context.Context, d *schema.ResourceData, m
 	}
 
 	if list != nil {
+		previousID := d.Get("previous_token_id").(string)
 		for _, t := range *list {
 			if t.Uuid != "" && t.Uuid == tokenID.String() {
 				if err := d.Set("service_user_id", serviceUserID.String()); err != nil {
@@ -115,15 +250,99 @@ func resourceServiceUserTokenRead(ctx context.Context, d *schema.ResourceData, m
 						return diag.FromErr(fmt.Errorf("failed to set expires_at: %w", err))
 					}
 				}
-				return nil
+				if err := d.Set("token_id", t.Uuid); err != nil {
+					return diag.FromErr(fmt.Errorf("failed to set token_id: %w", err))
+				}
+				if !t.CreatedAt.IsZero() {
+					if err := d.Set("created_at", t.CreatedAt.UTC().Format(time.RFC3339)); err != nil {
+						return diag.FromErr(fmt.Errorf("failed to set created_at: %w", err))
+					}
+				}
+				// states written before current_value existed only have value
+				if d.Get("current_value").(string) == "" {
+					if err := d.Set("current_value", d.Get("value")); err != nil {
+						return diag.FromErr(fmt.Errorf("failed to set current_value: %w", err))
+					}
+				}
+				if previousID != "" && !previousFound(*list, previousID) {
+					tflog.Info(ctx, "Previous service user token was deleted outside of Terraform", map[string]interface{}{"token_id": previousID})
+					if err := setPreviousServiceUserToken(d, "", "", ""); err != nil {
+						return diag.FromErr(err)
+					}
+				}
+				return setServiceUserTokenRotateAt(d)
 			}
 		}
 	}
 
 	d.SetId("")
+	if list != nil && previousFound(*list, d.Get("previous_token_id").(string)) {
+		return diag.Diagnostics{{
+			Severity: diag.Warning,
+			Summary:  "Previous service user token is no longer tracked",
+			Detail: fmt.Sprintf("The current token of service user %s was deleted outside of Terraform, so the resource is recreated. "+
+				"The previous token %s is still valid and is not deleted by Terraform anymore; delete it manually if it is no longer needed.",
+				serviceUserID, d.Get("previous_token_id").(string)),
+		}}
+	}
 	return nil
 }
 
+// resourceServiceUserTokenUpdate rotates the token and deletes the previous one when the plan marked them
+// unknown, other changes only touch the rotation settings kept in the state.
+func resourceServiceUserTokenUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client.Client)
+
+	serviceUserUUID, err := uuid.FromString(d.Get("service_user_id").(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	_, overlap, _, err := tokenRotation(d.Get("rotation").([]interface{}))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// rotated attributes are unknown in the plan, so the prior values come from the state
+	oldTokenID, _ := d.GetChange("token_id")
+	oldValue, _ := d.GetChange("current_value")
+	previousID, _ := d.GetChange("previous_token_id")
+	previousValue, _ := d.GetChange("previous_value")
+	retireAt, _ := d.GetChange("previous_retire_at")
+
+	// only act on what was planned, a rotation falling due between plan and apply waits for the next plan
+	rotate, retire := plannedRotation(d)
+	now := time.Now()
+
+	previous := [3]string{previousID.(string), previousValue.(string), retireAt.(string)}
+	if previous[0] != "" && (retire || rotate) {
+		if err := deleteServiceUserToken(ctx, c, &serviceUserUUID, previous[0]); err != nil {
+			return diag.FromErr(err)
+		}
+		previous = [3]string{}
+	}
+
+	if rotate {
+		tok, err := createServiceUserToken(ctx, c, d, &serviceUserUUID)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		if overlap > 0 {
+			previous = [3]string{oldTokenID.(string), oldValue.(string), now.Add(overlap).UTC().Format(time.RFC3339)}
+		} else if err := deleteServiceUserToken(ctx, c, &serviceUserUUID, oldTokenID.(string)); err != nil {
+			return diag.FromErr(err)
+		}
+		if err := setCurrentServiceUserToken(d, tok); err != nil {
+			return diag.FromErr(err)
+		}
+		tflog.Info(ctx, "Rotated service user token", map[string]interface{}{"previous_token_id": oldTokenID, "token_id": *tok.Uuid})
+	}
+
+	if err := setPreviousServiceUserToken(d, previous[0], previous[1], previous[2]); err != nil {
+		return diag.FromErr(err)
+	}
+	return resourceServiceUserTokenRead(ctx, d, meta)
+}
+
 func resourceServiceUserTokenDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client.Client)
 
@@ -131,9 +350,147 @@ func resourceServiceUserTokenDelete(ctx context.Context, d *schema.ResourceData,
 	if err != nil {
 		return diag.FromErr(err)
 	}
+	if current, ok := d.GetOk("token_id"); ok {
+		if tokenID, err = uuid.FromString(current.(string)); err != nil {
+			return diag.Errorf("invalid token_id %q: %s", current, err)
+		}
+	}
 	if err := c.DeleteServiceUserToken(ctx, &serviceUserID, &tokenID); err != nil {
 		return diag.FromErr(err)
 	}
+	if previousID := d.Get("previous_token_id").(string); previousID != "" {
+		if err := deleteServiceUserToken(ctx, c, &serviceUserID, previousID); err != nil {
+			return diag.FromErr(err)
+		}
+	}
 	d.SetId("")
 	return nil
 }
+
+// issues a token with the name and the expires_at or expires_in of the resource
+func createServiceUserToken(ctx context.Context, c *client.Client, d *schema.ResourceData, serviceUserUUID *uuid.UUID) (*iam.IamServiceUserTokenWithSecret, error) {
+	expiresAt, err := configuredExpiry(d, "expires_at", time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	tok, err := c.CreateServiceUserToken(ctx, serviceUserUUID, d.Get("name").(string), expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	if tok.Secret == nil || tok.Uuid == nil {
+		return nil, fmt.Errorf("empty token response")
+	}
+	return tok, nil
+}
+
+func deleteServiceUserToken(ctx context.Context, c *client.Client, serviceUserUUID *uuid.UUID, tokenID string) error {
+	tokenUUID, err := uuid.FromString(tokenID)
+	if err != nil {
+		return fmt.Errorf("invalid token id %q: %w", tokenID, err)
+	}
+	if err := c.DeleteServiceUserToken(ctx, serviceUserUUID, &tokenUUID); err != nil {
+		return fmt.Errorf("failed to delete service user token %s: %w", tokenID, err)
+	}
+	return nil
+}
+
+func setCurrentServiceUserToken(d *schema.ResourceData, tok *iam.IamServiceUserTokenWithSecret) error {
+	createdAt := time.Now()
+	if tok.CreatedAt != nil && !tok.CreatedAt.IsZero() {
+		createdAt = *tok.CreatedAt
+	}
+	if err := d.Set("value", *tok.Secret); err != nil {
+		return fmt.Errorf("error setting token value: %w", err)
+	}
+	if err := d.Set("current_value", *tok.Secret); err != nil {
+		return fmt.Errorf("failed to set current_value: %w", err)
+	}
+	if err := d.Set("token_id", *tok.Uuid); err != nil {
+		return fmt.Errorf("failed to set token_id: %w", err)
+	}
+	if err := d.Set("created_at", createdAt.UTC().Format(time.RFC3339)); err != nil {
+		return fmt.Errorf("failed to set created_at: %w", err)
+	}
+	return nil
+}
+
+func setPreviousServiceUserToken(d *schema.ResourceData, id, value, retireAt string) error {
+	if err := d.Set("previous_token_id", id); err != nil {
+		return fmt.Errorf("failed to set previous_token_id: %w", err)
+	}
+	if err := d.Set("previous_value", value); err != nil {
+		return fmt.Errorf("failed to set previous_value: %w", err)
+	}
+	if err := d.Set("previous_retire_at", retireAt); err != nil {
+		return fmt.Errorf("failed to set previous_retire_at: %w", err)
+	}
+	return nil
+}
+
+func setServiceUserTokenRotateAt(d *schema.ResourceData) diag.Diagnostics {
+	rotateAfter, _, ok, err := tokenRotation(d.Get("rotation").([]interface{}))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	rotateAt := ""
+	if createdAt := parseStateTime(d.Get("created_at").(string)); ok && !createdAt.IsZero() {
+		rotateAt = createdAt.Add(rotateAfter).UTC().Format(time.RFC3339)
+	}
+	if err := d.Set("rotate_at", rotateAt); err != nil {
+		return diag.FromErr(fmt.Errorf("failed to set rotate_at: %w", err))
+	}
+	return nil
+}
+
+// reports whether the token tokenID is in the list, false for an empty id
+func previousFound(tokens []iam.IamServiceUserToken, tokenID string) bool {
+	if tokenID == "" {
+		return false
+	}
+	for _, t := range tokens {
+		if t.Uuid == tokenID {
+			return true
+		}
+	}
+	return false
+}
+
+// plannedRotation reports the steps the plan marked unknown: token_id for a rotation and previous_token_id
+// for the deletion of the previous token
+func plannedRotation(d *schema.ResourceData) (rotate, retire bool) {
+	return d.HasChange("token_id"), d.HasChange("previous_token_id")
+}
+
+// reads the rotation block of sotoon_iam_service_user_token, ok is false when the block is absent
+func tokenRotation(raw []interface{}) (rotateAfter, overlap time.Duration, ok bool, err error) {
+	if len(raw) == 0 || raw[0] == nil {
+		return 0, 0, false, nil
+	}
+	m := raw[0].(map[string]interface{})
+	if rotateAfter, err = time.ParseDuration(m["rotate_after"].(string)); err != nil {
+		return 0, 0, false, fmt.Errorf("invalid rotate_after: %w", err)
+	}
+	if overlap, err = time.ParseDuration(m["overlap"].(string)); err != nil {
+		return 0, 0, false, fmt.Errorf("invalid overlap: %w", err)
+	}
+	return rotateAfter, overlap, true, nil
+}
+
+// rotationDue reports whether the current token, created at createdAt, is due for rotation and whether the
+// previous token, kept until retireAt, is due for deletion. A zero rotateAfter disables rotation and a zero
+// retireAt means there is no previous token.
+func rotationDue(createdAt time.Time, rotateAfter time.Duration, retireAt, now time.Time) (rotate, retire bool) {
+	rotate = rotateAfter > 0 && !createdAt.IsZero() && !now.Before(createdAt.Add(rotateAfter))
+	retire = !retireAt.IsZero() && !now.Before(retireAt)
+	return rotate, retire
+}
+
+// parses an RFC3339 time of the state, returning the zero time when it is empty or invalid
+func parseStateTime(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
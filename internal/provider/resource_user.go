@@ -3,9 +3,16 @@ package provider
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	uuid "github.com/satori/go.uuid"
+	iam "github.com/sotoon/sotoon-sdk-go/sdk/core/iam_v1"
 	"github.com/sotoon/terraform-provider-sotoon/internal/client"
 )
 
@@ -15,7 +22,9 @@ func resourceUser() *schema.Resource {
 		Description:   "Manages an IAM user within a Sotoon workspace.",
 		CreateContext: resourceUserCreate,
 		ReadContext:   resourceUserRead,
+		UpdateContext: resourceUserUpdate,
 		DeleteContext: resourceUserDelete,
+		CustomizeDiff: resourceUserCustomizeDiff,
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
@@ -41,6 +50,58 @@ func resourceUser() *schema.Resource {
 				Computed:    true,
 				Description: "The display name of the user.",
 			},
+			"status": {
+				Type:     schema.TypeString,
+				Computed: true,
+				Description: "One of `invited` (invitation sent, not accepted yet), `expired` (invitation not accepted in time, " +
+					"it is re-sent on the next apply), `active` or `suspended`.",
+			},
+			"invitation_uuid": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "UUID of the last invitation sent by this resource. Empty if the user was already in the workspace.",
+			},
+			"invited_at": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Time the last invitation was sent, in RFC3339 format.",
+			},
+			"invitation_expires_at": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Time the last invitation expires, in RFC3339 format. Derived from `invited_at` and `invitation_validity`.",
+			},
+			"invitation_validity": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      defaultInvitationValidity,
+				ValidateFunc: validateDuration,
+				Description:  "How long an invitation is considered valid, as a duration such as `72h`. The API does not return an expiry, so it is derived from this value.",
+			},
+			"wait_for_acceptance": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateDuration,
+				Description:  "If set, apply waits up to this duration (e.g. `30m`) for an invitation to be accepted and fails if it is not.",
+			},
+			"suspended": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Computed:    true,
+				Description: "Whether the user is suspended in the workspace. Leave unset to not manage suspension. Has no effect until the invitation is accepted. The user the provider authenticates as cannot be suspended.",
+			},
+			"remove_on_destroy": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "If true, destroying the resource removes the user from the workspace. The API cannot revoke invitations, so a user who has not accepted the invitation yet is only removed from the state, with a warning. If false, the user is only removed from the state.",
+			},
+			"force_detach_bindings": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "If true together with `remove_on_destroy`, the user is first removed from every group and unbound from every role in the workspace.",
+			},
 		},
 	}
 }
@@ -53,9 +114,8 @@ func resourceUserCreate(ctx context.Context, d *schema.ResourceData, meta interf
 	if err != nil {
 		//
 		if errors.Is(err, client.ErrNotFound) {
-			_, inviteErr := c.InviteUser(ctx, email)
-			if inviteErr != nil {
-				return diag.Errorf("Failed to invite user with email '%s': %s", email, inviteErr)
+			if diags := inviteUser(ctx, c, d, email); diags.HasError() {
+				return diags
 			}
 		} else {
 			return diag.FromErr(err)
@@ -63,6 +123,14 @@ func resourceUserCreate(ctx context.Context, d *schema.ResourceData, meta interf
 	}
 	d.SetId(email)
 
+	if diags := waitForAcceptance(ctx, c, d); diags.HasError() {
+		return diags
+	}
+	if !d.GetRawConfig().GetAttr("suspended").IsNull() {
+		if diags := applyUserSuspension(ctx, c, d); diags.HasError() {
+			return diags
+		}
+	}
 	return resourceUserRead(ctx, d, meta)
 }
 
@@ -72,11 +140,15 @@ func resourceUserRead(ctx context.Context, d *schema.ResourceData, meta interfac
 
 	user, err := c.GetUserByEmail(ctx, userEmail)
 	if err != nil {
-		if errors.Is(err, client.ErrNotFound) {
+		if !errors.Is(err, client.ErrNotFound) {
+			return diag.FromErr(err)
+		}
+		// a user who has not accepted the invitation is not listed in the workspace yet
+		if d.Get("invited_at").(string) == "" {
 			d.SetId("")
 			return nil
 		}
-		return diag.FromErr(err)
+		return setInvitationStatus(d)
 	}
 
 	if err := d.Set("email", user.Email); err != nil {
@@ -88,12 +160,284 @@ func resourceUserRead(ctx context.Context, d *schema.ResourceData, meta interfac
 	if err := d.Set("user_uuid", user.Uuid); err != nil {
 		return diag.FromErr(err)
 	}
+	status := userStatusActive
+	if user.IsSuspended {
+		status = userStatusSuspended
+	}
+	if err := d.Set("status", status); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("suspended", user.IsSuspended); err != nil {
+		return diag.FromErr(err)
+	}
+	// keeps the destroy options explicit after import, they have no remote counterpart
+	if err := d.Set("remove_on_destroy", d.Get("remove_on_destroy").(bool)); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("force_detach_bindings", d.Get("force_detach_bindings").(bool)); err != nil {
+		return diag.FromErr(err)
+	}
 
 	return nil
 }
 
-// resourceUserDelete "disowns" the user from the state without deleting it from Sotoon.
+// resourceUserUpdate re-sends an expired invitation and applies suspension changes; the other arguments only
+// take effect on later applies or in resourceUserDelete.
+func resourceUserUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client.Client)
+
+	if o, _ := d.GetChange("status"); o.(string) == userStatusExpired {
+		if diags := inviteUser(ctx, c, d, d.Id()); diags.HasError() {
+			return diags
+		}
+		tflog.Info(ctx, "Re-sent expired invitation", map[string]interface{}{"email": d.Id()})
+		if diags := waitForAcceptance(ctx, c, d); diags.HasError() {
+			return diags
+		}
+	}
+	if d.HasChange("suspended") {
+		if diags := applyUserSuspension(ctx, c, d); diags.HasError() {
+			return diags
+		}
+	}
+
+	return resourceUserRead(ctx, d, meta)
+}
+
+// resourceUserCustomizeDiff rejects suspending the provider's own user and plans a new invitation for users
+// whose invitation expired.
+func resourceUserCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if c, ok := meta.(*client.Client); ok && d.HasChange("suspended") && d.Get("suspended").(bool) &&
+		isProviderUser(d.Get("user_uuid").(string), c.UserID) {
+		return fmt.Errorf("refusing to suspend %s: it is the user the provider authenticates as", d.Get("email").(string))
+	}
+	if d.Id() == "" || d.Get("status").(string) != userStatusExpired {
+		return nil
+	}
+	if err := d.SetNew("status", userStatusInvited); err != nil {
+		return err
+	}
+	for _, k := range []string{"invitation_uuid", "invited_at", "invitation_expires_at"} {
+		if err := d.SetNewComputed(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyUserSuspension suspends or reactivates the user to match the suspended argument. A user who has not accepted
+// the invitation is skipped, the next apply retries once they have joined.
+func applyUserSuspension(ctx context.Context, c *client.Client, d *schema.ResourceData) diag.Diagnostics {
+	suspend := d.Get("suspended").(bool)
+
+	user, err := c.GetUserByEmail(ctx, d.Id())
+	if err != nil {
+		if errors.Is(err, client.ErrNotFound) {
+			tflog.Warn(ctx, "User has not joined the workspace yet, suspension not applied", map[string]interface{}{"email": d.Id()})
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+	if user.IsSuspended == suspend {
+		return nil
+	}
+
+	userUUID, err := uuid.FromString(user.Uuid)
+	if err != nil {
+		return diag.Errorf("invalid user UUID %q: %s", user.Uuid, err)
+	}
+	if !suspend {
+		if err := c.AllowUser(ctx, &userUUID); err != nil {
+			return diag.Errorf("failed to reactivate user %s: %s", d.Id(), err)
+		}
+		return nil
+	}
+	if isProviderUser(user.Uuid, c.UserID) {
+		return diag.Errorf("refusing to suspend %s: it is the user the provider authenticates as", d.Id())
+	}
+	if err := c.SuspendUser(ctx, &userUUID); err != nil {
+		return diag.Errorf("failed to suspend user %s: %s", d.Id(), err)
+	}
+	return nil
+}
+
+// inviteUser sends an invitation and records it in the state.
+func inviteUser(ctx context.Context, c *client.Client, d *schema.ResourceData, email string) diag.Diagnostics {
+	invitation, inviteErr := c.InviteUser(ctx, email)
+	if inviteErr != nil {
+		return diag.Errorf("Failed to invite user with email '%s': %s", email, inviteErr)
+	}
+	if err := d.Set("invitation_uuid", invitation.Uuid); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("invited_at", invitation.CreatedAt.UTC().Format(time.RFC3339)); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("name", invitation.Name); err != nil {
+		return diag.FromErr(err)
+	}
+	return setInvitationStatus(d)
+}
+
+// setInvitationStatus derives the invitation expiry and the invited or expired status from the state.
+func setInvitationStatus(d *schema.ResourceData) diag.Diagnostics {
+	validity, err := time.ParseDuration(d.Get("invitation_validity").(string))
+	if err != nil {
+		return diag.Errorf("invalid invitation_validity: %s", err)
+	}
+	expiresAt, expired, err := invitationExpiry(d.Get("invited_at").(string), validity, time.Now())
+	if err != nil {
+		return diag.Errorf("invalid invited_at: %s", err)
+	}
+	if err := d.Set("invitation_expires_at", expiresAt.Format(time.RFC3339)); err != nil {
+		return diag.FromErr(err)
+	}
+	status := userStatusInvited
+	if expired {
+		status = userStatusExpired
+	}
+	if err := d.Set("status", status); err != nil {
+		return diag.FromErr(err)
+	}
+	return nil
+}
+
+// waitForAcceptance blocks until the user shows up in the workspace when wait_for_acceptance is set.
+func waitForAcceptance(ctx context.Context, c *client.Client, d *schema.ResourceData) diag.Diagnostics {
+	raw, ok := d.GetOk("wait_for_acceptance")
+	if !ok {
+		return nil
+	}
+	timeout, err := time.ParseDuration(raw.(string))
+	if err != nil {
+		return diag.Errorf("invalid wait_for_acceptance: %s", err)
+	}
+
+	email := d.Id()
+	deadline := time.Now().Add(timeout)
+	for {
+		_, err := c.GetUserByEmail(ctx, email)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, client.ErrNotFound) {
+			return diag.FromErr(err)
+		}
+		if time.Now().Add(invitationPollInterval).After(deadline) {
+			return diag.Errorf("invitation for %s was not accepted within %s", email, timeout)
+		}
+		tflog.Debug(ctx, "Waiting for invitation to be accepted", map[string]interface{}{"email": email})
+		select {
+		case <-ctx.Done():
+			return diag.FromErr(ctx.Err())
+		case <-time.After(invitationPollInterval):
+		}
+	}
+}
+
+// resourceUserDelete "disowns" the user from the state without deleting it from Sotoon, unless
+// remove_on_destroy is set. A pending invitation cannot be revoked, so it is only disowned with a warning.
 func resourceUserDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if !d.Get("remove_on_destroy").(bool) {
+		d.SetId("")
+		return nil
+	}
+
+	c := meta.(*client.Client)
+	user, err := c.GetUserByEmail(ctx, d.Id())
+	if err != nil {
+		if !errors.Is(err, client.ErrNotFound) {
+			return diag.FromErr(err)
+		}
+		pending, email := d.Get("invited_at").(string) != "", d.Id()
+		d.SetId("")
+		if pending {
+			return diag.Diagnostics{{
+				Severity: diag.Warning,
+				Summary:  "Pending invitation was not revoked",
+				Detail: fmt.Sprintf("%s has not accepted the invitation yet. The API cannot revoke invitations, so the invitation stays valid "+
+					"and the user is only removed from the state.", email),
+			}}
+		}
+		return nil
+	}
+	userUUID, err := uuid.FromString(user.Uuid)
+	if err != nil {
+		return diag.Errorf("invalid user uuid %q: %s", user.Uuid, err)
+	}
+
+	if d.Get("force_detach_bindings").(bool) {
+		if diags := detachUserBindings(ctx, c, userUUID); diags.HasError() {
+			return diags
+		}
+	}
+
+	if err := c.RemoveUserFromWorkspace(ctx, &userUUID); err != nil {
+		return diag.Errorf("failed to remove user %s from workspace: %s", userUUID, err)
+	}
+	tflog.Info(ctx, "Removed user from workspace", map[string]interface{}{"email": d.Id(), "user_uuid": userUUID.String()})
+
 	d.SetId("")
 	return nil
 }
+
+// detachUserBindings removes the user from all groups and unbinds all roles the workspace reports for it.
+func detachUserBindings(ctx context.Context, c *client.Client, userUUID uuid.UUID) diag.Diagnostics {
+	detailed, err := c.GetUserDetailed(ctx, &userUUID)
+	if err != nil {
+		return diag.Errorf("failed to read groups and roles of user %s: %s", userUUID, err)
+	}
+
+	groupIDs, roleIDs, err := userDetachOrder(detailed)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	for _, groupID := range groupIDs {
+		if err := c.RemoveUserFromGroup(ctx, groupID, userUUID.String()); err != nil {
+			return diag.Errorf("failed to remove user %s from group %s: %s", userUUID, groupID, err)
+		}
+	}
+	for _, roleUUID := range roleIDs {
+		if err := c.UnbindRoleFromUser(ctx, &roleUUID, &userUUID); err != nil {
+			return diag.Errorf("unbind user %s from role %s failed: %s", userUUID, roleUUID, err)
+		}
+	}
+
+	tflog.Info(ctx, "Detached user bindings", map[string]interface{}{"user_uuid": userUUID.String(), "groups": len(detailed.Groups), "roles": len(detailed.Roles)})
+	return nil
+}
+
+// userDetachOrder lists the groups and then the roles to detach a user from, each sorted so removal is deterministic
+func userDetachOrder(detailed *iam.IamUserWorkspaceDetailedUser) ([]string, []uuid.UUID, error) {
+	groupIDs := make([]string, 0, len(detailed.Groups))
+	for _, g := range detailed.Groups {
+		groupIDs = append(groupIDs, g.Uuid)
+	}
+	sort.Strings(groupIDs)
+
+	roleIDs := make([]uuid.UUID, 0, len(detailed.Roles))
+	for _, r := range detailed.Roles {
+		roleUUID, err := uuid.FromString(r.Uuid)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid role uuid %q: %s", r.Uuid, err)
+		}
+		roleIDs = append(roleIDs, roleUUID)
+	}
+	sort.Slice(roleIDs, func(i, j int) bool { return roleIDs[i].String() < roleIDs[j].String() })
+	return groupIDs, roleIDs, nil
+}
+
+// returns the expiry of an invitation sent at invitedAt (RFC3339) and whether it passed at now
+func invitationExpiry(invitedAt string, validity time.Duration, now time.Time) (time.Time, bool, error) {
+	sent, err := time.Parse(time.RFC3339, invitedAt)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	expiresAt := sent.Add(validity)
+	return expiresAt, now.After(expiresAt), nil
+}
+
+// reports whether userUUID is the user the provider authenticates as
+func isProviderUser(userUUID, providerUserID string) bool {
+	return userUUID != "" && strings.EqualFold(userUUID, providerUserID)
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	uuid "github.com/satori/go.uuid"
 	"github.com/sotoon/terraform-provider-sotoon/internal/client"
@@ -17,7 +18,17 @@ func resourceUserGroupMembership() *schema.Resource {
 		Description:   "Manages the membership of a user in one or more IAM groups.",
 		CreateContext: resourceUserGroupMembershipCreate,
 		ReadContext:   resourceUserGroupMembershipRead,
+		UpdateContext: resourceUserGroupMembershipUpdate,
 		DeleteContext: resourceUserGroupMembershipDelete,
+		CustomizeDiff: customdiff.All(
+			customdiff.ComputedIf("bindings_hash", func(ctx context.Context, d *schema.ResourceDiff, meta interface{}) bool {
+				return d.HasChanges("user_ids", "user_emails")
+			}),
+			customdiff.ComputedIf("managed_user_ids", func(ctx context.Context, d *schema.ResourceDiff, meta interface{}) bool {
+				return d.HasChanges("user_ids", "user_emails", "exclusive")
+			}),
+			resolveUserEmailsDiff,
+		),
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
@@ -34,15 +45,43 @@ func resourceUserGroupMembership() *schema.Resource {
 				Description: "The UUID of the group to add users to.",
 			},
 			"user_ids": {
-				Type:        schema.TypeSet,
-				Required:    true,
-				ForceNew:    true,
-				MinItems:    1,
-				Description: "A list of user UUIDs to add to the group.",
+				Type:         schema.TypeSet,
+				Optional:     true,
+				MinItems:     1,
+				AtLeastOneOf: []string{"user_ids", "user_emails"},
+				Description:  "A list of user UUIDs to add to the group.",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"user_emails": {
+				Type:         schema.TypeSet,
+				Optional:     true,
+				MinItems:     1,
+				AtLeastOneOf: []string{"user_ids", "user_emails"},
+				Description:  "Emails of workspace members to add to the group, resolved to user UUIDs at plan time. Can be combined with `user_ids`.",
 				Elem: &schema.Schema{
 					Type: schema.TypeString,
 				},
 			},
+			"resolved_user_ids": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "User UUID of every email in `user_emails`. An email keeps its UUID for as long as it stays in `user_emails`.",
+			},
+			"exclusive": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "If true, `user_ids` is authoritative: members added outside of Terraform are reported as drift and removed on apply.",
+			},
+			"managed_user_ids": {
+				Type:        schema.TypeSet,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "User UUIDs this resource added to the group. Only these are removed on destroy; members that existed before are left in place.",
+			},
 			"bindings_hash": {
 				Type:        schema.TypeString,
 				Computed:    true,
@@ -61,7 +100,11 @@ func resourceUserGroupMembershipCreate(ctx context.Context, d *schema.ResourceDa
 		return diag.Errorf("invalid group_id: %s", err)
 	}
 
-	sortedUserIds := uniqueSorted(fromSchemaSetToStrings(d.Get("user_ids").(*schema.Set)))
+	emailUserIds, err := applyUserEmails(ctx, c, d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	sortedUserIds := uniqueSorted(append(fromSchemaSetToStrings(d.Get("user_ids").(*schema.Set)), emailUserIds...))
 
 	usersList, err := c.GetAllGroupUserList(ctx, &groupUUID)
 	if err != nil {
@@ -80,10 +123,21 @@ func resourceUserGroupMembershipCreate(ctx context.Context, d *schema.ResourceDa
 		}
 	}
 
+	for _, uid := range idsToRemove(nil, sortedUserIds, remoteUsersID, d.Get("exclusive").(bool)) {
+		if err := c.RemoveUserFromGroup(ctx, groupID, uid); err != nil {
+			return diag.Errorf("failed to remove user %s from group %s: %s", uid, groupID, err)
+		}
+	}
+
+	if err := d.Set("managed_user_ids", toAddList); err != nil {
+		return diag.FromErr(fmt.Errorf("failed to set managed_user_ids: %w", err))
+	}
+
 	bindHash := hashOfIDs(sortedUserIds)
 	if err := d.Set("bindings_hash", bindHash); err != nil {
 		return diag.FromErr(fmt.Errorf("failed to set bindings_hash: %w", err))
 	}
+	// the id is fixed at creation, bindings_hash follows later in-place updates
 	d.SetId(groupUUID.String() + ":" + bindHash)
 
 	return resourceUserGroupMembershipRead(ctx, d, meta)
@@ -98,7 +152,8 @@ func resourceUserGroupMembershipRead(ctx context.Context, d *schema.ResourceData
 		return nil
 	}
 
-	sortedUserIds := uniqueSorted(fromSchemaSetToStrings(d.Get("user_ids").(*schema.Set)))
+	localUserIds := fromSchemaSetToStrings(d.Get("user_ids").(*schema.Set))
+	sortedUserIds := uniqueSorted(append(localUserIds, resolvedIDs(fromSchemaSetToStrings(d.Get("user_emails").(*schema.Set)), stringMap(d.Get("resolved_user_ids").(map[string]interface{})))...))
 
 	usersList, err := c.GetAllGroupUserList(ctx, &groupUUID)
 	if err != nil {
@@ -109,34 +164,92 @@ func resourceUserGroupMembershipRead(ctx context.Context, d *schema.ResourceData
 		remoteUsersID = append(remoteUsersID, u.Uuid)
 	}
 	remoteUsersID = uniqueSorted(remoteUsersID)
-	eff := intersect(toSet(sortedUserIds), toSet(remoteUsersID))
-	effective := uniqueSorted(setKeys(eff))
+	effective := effectiveIDs(sortedUserIds, remoteUsersID, d.Get("exclusive").(bool))
 
-	if err := d.Set("user_ids", effective); err != nil {
+	emailOnlyIds, err := readResolved(d, "user_emails", "resolved_user_ids", "user_ids", remoteUsersID)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("user_ids", diff(toSet(effective), toSet(emailOnlyIds))); err != nil {
 		return diag.FromErr(fmt.Errorf("failed to set user_ids: %w", err))
 	}
 
-	bindHash := ""
-	if v, ok := d.GetOk("bindings_hash"); ok && v.(string) != "" {
-		bindHash = v.(string)
-	} else {
-		bindHash = hashOfIDs(effective)
-		if err := d.Set("bindings_hash", bindHash); err != nil {
+	managed := effective
+	if tracksManagedIDs(d, "managed_user_ids") {
+		managed = uniqueSorted(setKeys(intersect(toSet(fromSchemaSetToStrings(d.Get("managed_user_ids").(*schema.Set))), toSet(remoteUsersID))))
+	}
+	if err := d.Set("managed_user_ids", managed); err != nil {
+		return diag.FromErr(fmt.Errorf("failed to set managed_user_ids: %w", err))
+	}
+
+	if d.Get("bindings_hash").(string) == "" {
+		if err := d.Set("bindings_hash", hashOfIDs(effective)); err != nil {
 			return diag.FromErr(fmt.Errorf("failed to set bindings_hash: %w", err))
 		}
 	}
 
-	d.SetId(groupUUID.String() + ":" + bindHash)
-
 	tflog.Info(ctx, "Read user group membership", map[string]interface{}{"group_id": groupID, "have": len(effective)})
 	return nil
 }
 
+func resourceUserGroupMembershipUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client.Client)
+
+	groupID := d.Get("group_id").(string)
+	groupUUID, err := uuid.FromString(groupID)
+	if err != nil {
+		return diag.Errorf("invalid group_id: %s", err)
+	}
+
+	if d.HasChanges("user_ids", "user_emails", "exclusive") {
+		oldManaged, _ := d.GetChange("managed_user_ids")
+		managedIds := fromSchemaSetToStrings(oldManaged.(*schema.Set))
+		emailUserIds, err := applyUserEmails(ctx, c, d)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		sortedUserIds := uniqueSorted(append(fromSchemaSetToStrings(d.Get("user_ids").(*schema.Set)), emailUserIds...))
+
+		usersList, err := c.GetAllGroupUserList(ctx, &groupUUID)
+		if err != nil {
+			return diag.Errorf("read group users: %s", err)
+		}
+		remoteUsersID := make([]string, 0, len(usersList))
+		for _, u := range usersList {
+			remoteUsersID = append(remoteUsersID, u.Uuid)
+		}
+		remoteUsersID = uniqueSorted(remoteUsersID)
+
+		toAddList, toRemoveList := memberDelta(sortedUserIds, remoteUsersID, managedIds, d.Get("exclusive").(bool))
+		if len(toAddList) > 0 {
+			if _, err := c.BulkAddUsersToGroup(ctx, groupUUID, toAddList); err != nil {
+				return diag.Errorf("add users to group %s: %s", groupID, err)
+			}
+		}
+
+		for _, uid := range toRemoveList {
+			if err := c.RemoveUserFromGroup(ctx, groupID, uid); err != nil {
+				return diag.Errorf("failed to remove user %s from group %s: %s", uid, groupID, err)
+			}
+		}
+
+		if err := d.Set("managed_user_ids", managedAfter(managedIds, toAddList, toRemoveList)); err != nil {
+			return diag.FromErr(fmt.Errorf("failed to set managed_user_ids: %w", err))
+		}
+		if err := d.Set("bindings_hash", hashOfIDs(sortedUserIds)); err != nil {
+			return diag.FromErr(fmt.Errorf("failed to set bindings_hash: %w", err))
+		}
+		tflog.Info(ctx, "Updated user group membership", map[string]interface{}{"group_id": groupID, "added": len(toAddList), "removed": len(toRemoveList)})
+	}
+
+	return resourceUserGroupMembershipRead(ctx, d, meta)
+}
+
 func resourceUserGroupMembershipDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	c := meta.(*client.Client)
 
 	groupID := d.Get("group_id").(string)
-	userIDs := d.Get("user_ids").(*schema.Set).List()
+	userIDs := d.Get("managed_user_ids").(*schema.Set).List()
 
 	for _, v := range userIDs {
 		uid := v.(string)
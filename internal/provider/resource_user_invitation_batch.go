@@ -0,0 +1,348 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/sotoon/terraform-provider-sotoon/internal/client"
+)
+
+// resourceUserInvitationBatch defines the schema and CRUD functions for the sotoon_iam_user_invitation_batch resource.
+func resourceUserInvitationBatch() *schema.Resource {
+	return &schema.Resource{
+		Description: "Invites a set of emails to the workspace with a single invite request. " +
+			"If the API rejects the request, every email is retried on its own so one bad address does not fail the batch. " +
+			"The API cannot revoke invitations, so removing an email or destroying the resource only stops tracking it.",
+		CreateContext: resourceUserInvitationBatchCreate,
+		ReadContext:   resourceUserInvitationBatchRead,
+		UpdateContext: resourceUserInvitationBatchUpdate,
+		DeleteContext: resourceUserInvitationBatchDelete,
+		CustomizeDiff: customdiff.All(
+			customdiff.ComputedIf("invitations", invitationBatchNeedsInvite),
+			customdiff.ComputedIf("pending_emails", invitationBatchNeedsInvite),
+		),
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "A stable identifier for this batch.",
+			},
+			"emails": {
+				Type:        schema.TypeSet,
+				Required:    true,
+				MinItems:    1,
+				Description: "Email addresses to invite. Emails added later are invited on the next apply.",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"invitation_validity": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      defaultInvitationValidity,
+				ValidateFunc: validateDuration,
+				Description:  "How long an invitation is considered valid, as a duration such as `72h`. Expired invitations are re-sent on the next apply.",
+			},
+			"invitations": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Status of every email, sorted by email.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"email": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Email address.",
+						},
+						"status": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "One of `invited`, `expired`, `active` (the email belongs to a workspace member) or `failed`.",
+						},
+						"invited_at": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Time the invitation was sent, in RFC3339 format. Empty if no invitation was sent.",
+						},
+						"expires_at": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Time the invitation expires, derived from `invited_at` and `invitation_validity`.",
+						},
+						"error": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Why the invitation failed, when `status` is `failed`. Failed invitations are retried on the next apply.",
+						},
+					},
+				},
+			},
+			"pending_emails": {
+				Type:        schema.TypeSet,
+				Computed:    true,
+				Description: "Emails with an invitation which is neither accepted nor expired.",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+		},
+	}
+}
+
+func invitationBatchNeedsInvite(ctx context.Context, d *schema.ResourceDiff, meta interface{}) bool {
+	if d.HasChange("emails") {
+		return true
+	}
+	return anyNeedsReinvite(expandInvitations(d.Get("invitations").([]interface{})))
+}
+
+func resourceUserInvitationBatchCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client.Client)
+
+	emails := uniqueSorted(fromSchemaSetToStrings(d.Get("emails").(*schema.Set)))
+	invitations, err := sendInvitations(ctx, c, emails)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("invitations", flattenInvitations(invitations)); err != nil {
+		return diag.FromErr(fmt.Errorf("failed to set invitations: %w", err))
+	}
+
+	// the id is fixed at creation, emails added later are invited in place
+	d.SetId(c.WorkspaceUUID.String() + ":" + hashOfIDs(emails))
+
+	return resourceUserInvitationBatchRead(ctx, d, meta)
+}
+
+func resourceUserInvitationBatchRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client.Client)
+
+	validity, err := time.ParseDuration(d.Get("invitation_validity").(string))
+	if err != nil {
+		return diag.Errorf("invalid invitation_validity: %s", err)
+	}
+	members, err := workspaceMemberEmails(ctx, c)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	invitations := refreshInvitations(expandInvitations(d.Get("invitations").([]interface{})), members, validity, time.Now())
+	pending := []string{}
+	for _, inv := range invitations {
+		if inv.Status == userStatusInvited {
+			pending = append(pending, inv.Email)
+		}
+	}
+
+	if err := d.Set("invitations", flattenInvitations(invitations)); err != nil {
+		return diag.FromErr(fmt.Errorf("failed to set invitations: %w", err))
+	}
+	if err := d.Set("pending_emails", pending); err != nil {
+		return diag.FromErr(fmt.Errorf("failed to set pending_emails: %w", err))
+	}
+	return nil
+}
+
+func resourceUserInvitationBatchUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client.Client)
+
+	o, _ := d.GetChange("invitations")
+	tracked := expandInvitations(o.([]interface{}))
+	desired := uniqueSorted(fromSchemaSetToStrings(d.Get("emails").(*schema.Set)))
+
+	sent, err := sendInvitations(ctx, c, emailsToInvite(desired, tracked))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	byEmail := map[string]invitationState{}
+	for _, inv := range tracked {
+		byEmail[inv.Email] = inv
+	}
+	for _, inv := range sent {
+		byEmail[inv.Email] = inv
+	}
+	invitations := make([]invitationState, 0, len(desired))
+	for _, e := range desired {
+		invitations = append(invitations, byEmail[e])
+		delete(byEmail, e)
+	}
+	for e := range byEmail {
+		tflog.Info(ctx, "Email removed from the batch, its invitation is not revoked", map[string]interface{}{"email": e})
+	}
+
+	if err := d.Set("invitations", flattenInvitations(invitations)); err != nil {
+		return diag.FromErr(fmt.Errorf("failed to set invitations: %w", err))
+	}
+	return resourceUserInvitationBatchRead(ctx, d, meta)
+}
+
+// resourceUserInvitationBatchDelete only removes the batch from the state, the API cannot revoke invitations.
+func resourceUserInvitationBatchDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	d.SetId("")
+	return nil
+}
+
+// sendInvitations invites the emails which are not workspace members yet in one request. When the request is
+// rejected each email is invited on its own, and emails the API still rejects are reported as failed.
+func sendInvitations(ctx context.Context, c *client.Client, emails []string) ([]invitationState, error) {
+	members, err := workspaceMemberEmails(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
+	out := []invitationState{}
+	toInvite := []string{}
+	for _, e := range emails {
+		if _, ok := members[strings.ToLower(e)]; ok {
+			out = append(out, invitationState{Email: e, Status: userStatusActive})
+			continue
+		}
+		toInvite = append(toInvite, e)
+	}
+	if len(toInvite) == 0 {
+		return out, nil
+	}
+
+	invitation, err := c.InviteUsers(ctx, toInvite)
+	if err == nil {
+		for _, e := range toInvite {
+			out = append(out, invitationState{Email: e, Status: userStatusInvited, InvitedAt: invitedAt(invitation.CreatedAt)})
+		}
+		return out, nil
+	}
+
+	tflog.Warn(ctx, "Batch invitation rejected, inviting each email separately", map[string]interface{}{"error": err.Error()})
+	for _, e := range toInvite {
+		invitation, err := c.InviteUser(ctx, e)
+		if err != nil {
+			out = append(out, invitationState{Email: e, Status: invitationStatusFailed, Error: err.Error()})
+			continue
+		}
+		out = append(out, invitationState{Email: e, Status: userStatusInvited, InvitedAt: invitedAt(invitation.CreatedAt)})
+	}
+	return out, nil
+}
+
+// lower-cased emails of the workspace members
+func workspaceMemberEmails(ctx context.Context, c *client.Client) (map[string]struct{}, error) {
+	users, err := c.GetWorkspaceUsers(ctx, c.WorkspaceUUID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workspace users: %w", err)
+	}
+	members := make(map[string]struct{}, len(users))
+	for _, u := range users {
+		members[strings.ToLower(u.Email)] = struct{}{}
+	}
+	return members, nil
+}
+
+// invitedAt formats the invitation time, falling back to now when the API leaves it empty
+func invitedAt(createdAt time.Time) string {
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	return createdAt.UTC().Format(time.RFC3339)
+}
+
+func expandInvitations(raw []interface{}) []invitationState {
+	out := make([]invitationState, 0, len(raw))
+	for _, r := range raw {
+		m, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		out = append(out, invitationState{
+			Email:     m["email"].(string),
+			Status:    m["status"].(string),
+			InvitedAt: m["invited_at"].(string),
+			ExpiresAt: m["expires_at"].(string),
+			Error:     m["error"].(string),
+		})
+	}
+	return out
+}
+
+func flattenInvitations(invitations []invitationState) []interface{} {
+	out := make([]interface{}, 0, len(invitations))
+	for _, inv := range invitations {
+		out = append(out, map[string]interface{}{
+			"email":      inv.Email,
+			"status":     inv.Status,
+			"invited_at": inv.InvitedAt,
+			"expires_at": inv.ExpiresAt,
+			"error":      inv.Error,
+		})
+	}
+	return out
+}
+
+// invitationState is one email tracked by sotoon_iam_user_invitation_batch
+type invitationState struct {
+	Email     string
+	Status    string
+	InvitedAt string
+	ExpiresAt string
+	Error     string
+}
+
+// refreshInvitations derives the status of every tracked email from the workspace members and the invitation time.
+// Failed emails keep their status until they are invited again.
+func refreshInvitations(tracked []invitationState, members map[string]struct{}, validity time.Duration, now time.Time) []invitationState {
+	out := make([]invitationState, 0, len(tracked))
+	for _, inv := range tracked {
+		if _, ok := members[strings.ToLower(inv.Email)]; ok {
+			inv.Status = userStatusActive
+			inv.Error = ""
+		} else if inv.Status != invitationStatusFailed {
+			expiresAt, expired, err := invitationExpiry(inv.InvitedAt, validity, now)
+			if err == nil {
+				inv.ExpiresAt = expiresAt.Format(time.RFC3339)
+				inv.Status = userStatusInvited
+				if expired {
+					inv.Status = userStatusExpired
+				}
+			}
+		}
+		out = append(out, inv)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Email < out[j].Email })
+	return out
+}
+
+// reports whether an invitation with this status is sent again on the next apply
+func needsReinvite(status string) bool {
+	return status == userStatusExpired || status == invitationStatusFailed
+}
+
+// reports whether any tracked invitation expired or failed
+func anyNeedsReinvite(tracked []invitationState) bool {
+	for _, inv := range tracked {
+		if needsReinvite(inv.Status) {
+			return true
+		}
+	}
+	return false
+}
+
+// emails of the batch which need an invitation: new ones and those whose invitation expired or failed
+func emailsToInvite(desired []string, tracked []invitationState) []string {
+	known := map[string]string{}
+	for _, inv := range tracked {
+		known[inv.Email] = inv.Status
+	}
+	out := []string{}
+	for _, e := range uniqueSorted(desired) {
+		if status, ok := known[e]; !ok || needsReinvite(status) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
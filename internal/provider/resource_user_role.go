@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	uuid "github.com/satori/go.uuid"
 	"github.com/sotoon/terraform-provider-sotoon/internal/client"
@@ -15,7 +17,18 @@ func resourceUserRole() *schema.Resource {
 		Description:   "Binds one or more users to a role within a Sotoon workspace (bulk).",
 		CreateContext: resourceUserRoleCreate,
 		ReadContext:   resourceUserRoleRead,
+		UpdateContext: resourceUserRoleUpdate,
 		DeleteContext: resourceUserRoleDelete,
+		CustomizeDiff: customdiff.All(
+			customdiff.ComputedIf("bindings_hash", func(ctx context.Context, d *schema.ResourceDiff, meta interface{}) bool {
+				return d.HasChanges("user_ids", "user_emails", "binding")
+			}),
+			customdiff.ComputedIf("managed_user_ids", func(ctx context.Context, d *schema.ResourceDiff, meta interface{}) bool {
+				return d.HasChanges("user_ids", "user_emails", "binding", "exclusive")
+			}),
+			resolveUserEmailsDiff,
+			validateBindingItems("role_id", "user_ids", ""),
+		),
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
@@ -32,20 +45,51 @@ func resourceUserRole() *schema.Resource {
 				Description: "Role UUID.",
 			},
 			"user_ids": {
-				Type:     schema.TypeSet,
-				Required: true,
-				ForceNew: true,
-				MinItems: 1,
+				Type:          schema.TypeSet,
+				Optional:      true,
+				MinItems:      1,
+				AtLeastOneOf:  []string{"user_ids", "user_emails", "binding"},
+				ConflictsWith: []string{"binding"},
 				Elem: &schema.Schema{
 					Type: schema.TypeString,
 				},
 				Description: "Set of user UUIDs to bind to the role.",
 			},
-			"items": {
+			"user_emails": {
+				Type:          schema.TypeSet,
+				Optional:      true,
+				MinItems:      1,
+				AtLeastOneOf:  []string{"user_ids", "user_emails", "binding"},
+				ConflictsWith: []string{"binding"},
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+				Description: "Emails of workspace members to bind to the role with the shared `items`, resolved to user UUIDs at plan time. Can be combined with `user_ids`.",
+			},
+			"resolved_user_ids": {
 				Type:        schema.TypeMap,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "User UUID of every email in `user_emails`. An email keeps its UUID for as long as it stays in `user_emails`.",
+			},
+			"binding": roleBindingSchema("User UUID to bind to the role.", "user_ids", "user_emails"),
+			"items": {
+				Type:          schema.TypeMap,
+				Optional:      true,
+				ConflictsWith: []string{"binding"},
+				Description:   "map of items related to this role. Items are read back for every bound user; a difference shows up as drift and the affected users are rebound in place.",
+			},
+			"exclusive": {
+				Type:        schema.TypeBool,
 				Optional:    true,
-				ForceNew:    true,
-				Description: "map of items related to this role.",
+				Default:     false,
+				Description: "If true, `user_ids` is authoritative: bindings added outside of Terraform are reported as drift and removed on apply.",
+			},
+			"managed_user_ids": {
+				Type:        schema.TypeSet,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "User UUIDs this resource bound to the role. Only these are unbound on destroy; bindings that existed before are left in place.",
 			},
 			"bindings_hash": {
 				Type:        schema.TypeString,
@@ -65,34 +109,26 @@ func resourceUserRoleCreate(ctx context.Context, d *schema.ResourceData, meta in
 		return diag.Errorf("invalid role_id: %s", err)
 	}
 
-	sortedUserIds := uniqueSorted(fromSchemaSetToStrings(d.Get("user_ids").(*schema.Set)))
-
-	usersList, err := c.GetRoleUsers(ctx, &roleUUID)
+	emailUserIds, err := applyUserEmails(ctx, c, d)
 	if err != nil {
-		return diag.Errorf("read users of role: %s", err)
+		return diag.FromErr(err)
 	}
-	remoteUsersID := make([]string, 0, len(usersList))
-	for _, u := range usersList {
-		remoteUsersID = append(remoteUsersID, u.Uuid)
+	desired := withSharedBindings(d, desiredBindings(d, "user_ids"), emailUserIds)
+	toAddList, _, err := syncUserRoleBindings(ctx, c, roleUUID, desired, nil, d.Get("exclusive").(bool))
+	if err != nil {
+		return diag.FromErr(err)
 	}
-	remoteUsersID = uniqueSorted(remoteUsersID)
 
-	toAddList := diff(toSet(sortedUserIds), toSet(remoteUsersID))
-	if len(toAddList) > 0 {
-		var itemsToAdd map[string]interface{}
-		if items, found := d.GetOk("items"); found {
-			itemsToAdd = items.(map[string]interface{})
-		}
-		if err := c.BulkAddUsersToRole(ctx, roleUUID, toAddList, itemsToAdd); err != nil {
-			return diag.Errorf("add users to role %s: %s", roleUUID, err)
-		}
+	if err := d.Set("managed_user_ids", toAddList); err != nil {
+		return diag.FromErr(fmt.Errorf("failed to set managed_user_ids: %w", err))
 	}
 
-	bindHash := hashOfIDs(sortedUserIds)
+	bindHash := hashOfIDs(bindingIDs(desired))
 
 	if err := d.Set("bindings_hash", bindHash); err != nil {
 		return diag.FromErr(fmt.Errorf("failed to set bindings_hash: %w", err))
 	}
+	// the id is fixed at creation, bindings_hash follows later in-place updates
 	d.SetId(roleUUID.String() + ":" + bindHash)
 	return resourceUserRoleRead(ctx, d, meta)
 }
@@ -107,7 +143,9 @@ func resourceUserRoleRead(ctx context.Context, d *schema.ResourceData, meta inte
 		return nil
 	}
 
-	sortedUserIds := uniqueSorted(fromSchemaSetToStrings(d.Get("user_ids").(*schema.Set)))
+	emailUserIds := resolvedIDs(fromSchemaSetToStrings(d.Get("user_emails").(*schema.Set)), stringMap(d.Get("resolved_user_ids").(map[string]interface{})))
+	desired := withSharedBindings(d, desiredBindings(d, "user_ids"), emailUserIds)
+	sortedUserIds := bindingIDs(desired)
 
 	usersList, err := c.GetRoleUsers(ctx, &roleUUID)
 	if err != nil {
@@ -115,32 +153,139 @@ func resourceUserRoleRead(ctx context.Context, d *schema.ResourceData, meta inte
 	}
 
 	remoteUsersID := make([]string, 0, len(usersList))
+	remoteItems := make(map[string][]map[string]string, len(usersList))
 	for _, u := range usersList {
 		remoteUsersID = append(remoteUsersID, u.Uuid)
+		remoteItems[u.Uuid] = u.Items
 	}
 	remoteUsersID = uniqueSorted(remoteUsersID)
 
-	eff := intersect(toSet(sortedUserIds), toSet(remoteUsersID))
-	effective := uniqueSorted(setKeys(eff))
+	effective := effectiveIDs(sortedUserIds, remoteUsersID, d.Get("exclusive").(bool))
 
-	if err := d.Set("user_ids", effective); err != nil {
-		return diag.FromErr(fmt.Errorf("failed to set user_ids: %w", err))
+	managed := effective
+	if tracksManagedIDs(d, "managed_user_ids") {
+		managed = uniqueSorted(setKeys(intersect(toSet(fromSchemaSetToStrings(d.Get("managed_user_ids").(*schema.Set))), toSet(remoteUsersID))))
 	}
+	// only members this resource bound, or every member in exclusive mode, are rebound on drift
+	rebindable := rebindableIDs(effective, managed, d.Get("exclusive").(bool))
 
-	bindHash := ""
-	if v, ok := d.GetOk("bindings_hash"); ok && v.(string) != "" {
-		bindHash = v.(string)
+	var diags diag.Diagnostics
+	if _, ok := d.GetOk("binding"); ok {
+		bindings, err := flattenBindings(effective, rebindable, desired, remoteItems)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		if err := d.Set("binding", bindings); err != nil {
+			return diag.FromErr(fmt.Errorf("failed to set binding: %w", err))
+		}
 	} else {
-		bindHash = hashOfIDs(effective)
-		if err := d.Set("bindings_hash", bindHash); err != nil {
+		emailOnlyIds, err := readResolved(d, "user_emails", "resolved_user_ids", "user_ids", remoteUsersID)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		if err := d.Set("user_ids", diff(toSet(effective), toSet(emailOnlyIds))); err != nil {
+			return diag.FromErr(fmt.Errorf("failed to set user_ids: %w", err))
+		}
+
+		if drifted := driftedItemIDs(rebindable, remoteItems, stringMap(d.Get("items").(map[string]interface{}))); len(drifted) > 0 {
+			var items map[string]string
+			items, diags = driftedSharedItems(drifted, remoteItems)
+			if diags.HasError() {
+				return diags
+			}
+			if err := d.Set("items", items); err != nil {
+				return diag.FromErr(fmt.Errorf("failed to set items: %w", err))
+			}
+		}
+	}
+
+	if err := d.Set("managed_user_ids", managed); err != nil {
+		return diag.FromErr(fmt.Errorf("failed to set managed_user_ids: %w", err))
+	}
+
+	if d.Get("bindings_hash").(string) == "" {
+		if err := d.Set("bindings_hash", hashOfIDs(effective)); err != nil {
+			return diag.FromErr(fmt.Errorf("failed to set bindings_hash: %w", err))
+		}
+	}
+
+	return diags
+}
+
+func resourceUserRoleUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	c := meta.(*client.Client)
+
+	roleID := d.Get("role_id").(string)
+	roleUUID, err := uuid.FromString(roleID)
+	if err != nil {
+		return diag.Errorf("invalid role_id: %s", err)
+	}
+
+	if d.HasChanges("user_ids", "user_emails", "binding", "items", "exclusive") {
+		oldManaged, _ := d.GetChange("managed_user_ids")
+		managedIds := fromSchemaSetToStrings(oldManaged.(*schema.Set))
+		emailUserIds, err := applyUserEmails(ctx, c, d)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		desired := withSharedBindings(d, desiredBindings(d, "user_ids"), emailUserIds)
+
+		toAddList, toRemoveList, err := syncUserRoleBindings(ctx, c, roleUUID, desired, managedIds, d.Get("exclusive").(bool))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+
+		if err := d.Set("managed_user_ids", managedAfter(managedIds, toAddList, toRemoveList)); err != nil {
+			return diag.FromErr(fmt.Errorf("failed to set managed_user_ids: %w", err))
+		}
+		if err := d.Set("bindings_hash", hashOfIDs(bindingIDs(desired))); err != nil {
 			return diag.FromErr(fmt.Errorf("failed to set bindings_hash: %w", err))
 		}
+		tflog.Info(ctx, "Updated user role", map[string]interface{}{"role_id": roleID, "added": len(toAddList), "removed": len(toRemoveList)})
+	}
+
+	return resourceUserRoleRead(ctx, d, meta)
+}
 
+// syncUserRoleBindings binds missing users with their items, rebinds managed users whose items drifted and
+// unbinds managed users which are no longer desired (every other user in exclusive mode, where every drifted
+// user is rebound too). It returns the added and removed user ids.
+func syncUserRoleBindings(ctx context.Context, c *client.Client, roleUUID uuid.UUID, desired map[string]map[string]string, managed []string, exclusive bool) ([]string, []string, error) {
+	usersList, err := c.GetRoleUsers(ctx, &roleUUID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read users of role: %w", err)
+	}
+	remoteUsersID := make([]string, 0, len(usersList))
+	remoteItems := make(map[string][]map[string]string, len(usersList))
+	for _, u := range usersList {
+		remoteUsersID = append(remoteUsersID, u.Uuid)
+		remoteItems[u.Uuid] = u.Items
 	}
+	remoteUsersID = uniqueSorted(remoteUsersID)
 
-	d.SetId(roleUUID.String() + ":" + bindHash)
+	sortedUserIds := bindingIDs(desired)
+	toAddList, toRemoveList := memberDelta(sortedUserIds, remoteUsersID, managed, exclusive)
+	toRebindList := driftedBindingIDs(rebindableIDs(setKeys(intersect(toSet(sortedUserIds), toSet(remoteUsersID))), managed, exclusive), remoteItems, desired)
 
-	return nil
+	toUnbindList := append(append([]string{}, toRebindList...), toRemoveList...)
+	for _, v := range toUnbindList {
+		u, err := uuid.FromString(v)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid user_id in list: %w", err)
+		}
+		if err := c.UnbindRoleFromUser(ctx, &roleUUID, &u); err != nil {
+			return nil, nil, fmt.Errorf("unbind user %s from role %s failed: %w", u.String(), roleUUID.String(), err)
+		}
+	}
+
+	toBindList := uniqueSorted(append(append([]string{}, toAddList...), toRebindList...))
+	if len(toBindList) > 0 {
+		if err := c.BulkAddUsersToRoleWithItems(ctx, roleUUID, toBindList, bindingItemsList(toBindList, desired)); err != nil {
+			return nil, nil, fmt.Errorf("add users to role %s: %w", roleUUID, err)
+		}
+	}
+
+	return toAddList, toRemoveList, nil
 }
 
 func resourceUserRoleDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
@@ -152,7 +297,7 @@ func resourceUserRoleDelete(ctx context.Context, d *schema.ResourceData, meta in
 		return diag.Errorf("invalid role_id %q: %s", roleID, err)
 	}
 
-	users := d.Get("user_ids").(*schema.Set).List()
+	users := d.Get("managed_user_ids").(*schema.Set).List()
 	for _, v := range users {
 		u, err := uuid.FromString(v.(string))
 		if err != nil {
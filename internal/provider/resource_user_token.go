@@ -2,10 +2,12 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	uuid "github.com/satori/go.uuid"
+	iam "github.com/sotoon/sotoon-sdk-go/sdk/core/iam_v1"
 
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
@@ -17,12 +19,15 @@ import (
 // resourceUserToken defines the schema and CRUD functions for the sotoon_iam_user_token resource.
 func resourceUserToken() *schema.Resource {
 	return &schema.Resource{
-		Description:   "Manages a user token for the current IAM user.",
+		Description: "Manages a user token for the current IAM user. " +
+			"Existing tokens can be imported by UUID to track their expiry, but the API only returns the secret when a token is created, so `value` stays empty for imported tokens.",
 		CreateContext: resourceUserTokenCreate,
 		ReadContext:   resourceUserTokenRead,
+		UpdateContext: resourceUserTokenUpdate,
 		DeleteContext: resourceUserTokenDelete,
+		CustomizeDiff: renewTokenDiff("expires_at"),
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: resourceUserTokenImport,
 		},
 		Schema: map[string]*schema.Schema{
 			"id": {
@@ -36,17 +41,53 @@ func resourceUserToken() *schema.Resource {
 				ForceNew:    true,
 				Description: "The name/label for the newly minted user token.",
 			},
+			"expires_at": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Computed:         true,
+				ForceNew:         true,
+				ValidateFunc:     validateExpiry,
+				DiffSuppressFunc: suppressEquivalentExpiry,
+				ConflictsWith:    []string{"expire_at", "expires_in"},
+				Description:      "Expiration timestamp in RFC3339 format (e.g. 2025-09-30T00:00:00Z), or a date such as `2025-09-30` for midnight UTC. Set from `expires_in` when that is used.",
+			},
 			"expire_at": {
-				Type:        schema.TypeString,
-				Optional:    true,
-				ForceNew:    true,
-				Description: "Expiration timestamp in RFC3339 format (e.g. 2025-09-30T00:00:00Z).",
+				Type:             schema.TypeString,
+				Optional:         true,
+				Computed:         true,
+				ForceNew:         true,
+				ValidateFunc:     validateExpiry,
+				DiffSuppressFunc: suppressEquivalentExpiry,
+				ConflictsWith:    []string{"expires_at", "expires_in"},
+				Deprecated:       "Use expires_at, which is named like the expiry of sotoon_iam_service_user_token.",
+				Description:      "Deprecated alias of `expires_at`.",
+			},
+			"expires_in": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ForceNew:         true,
+				ValidateFunc:     validateLongDuration,
+				DiffSuppressFunc: suppressImportedExpiresIn,
+				ConflictsWith:    []string{"expires_at", "expire_at"},
+				Description:      "Lifetime of the token from its creation, as a duration such as `90d` or `36h`. Changing it replaces the token. Setting it on an imported token does not replace the token, its expiry stays as read from the API.",
+			},
+			"renew_before": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validateLongDuration,
+				RequiredWith: []string{"expires_in"},
+				Description:  "Replace the token on the first plan within this duration of its expiry, such as `7d`. Requires `expires_in`, so the new token gets a fresh expiry.",
 			},
 			"value": {
 				Type:        schema.TypeString,
 				Computed:    true,
 				Sensitive:   true,
-				Description: "The newly issued token value.",
+				Description: "The newly issued token value. Empty for imported tokens.",
+			},
+			"imported": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the token was imported rather than created by this resource.",
 			},
 		},
 	}
@@ -56,18 +97,18 @@ func resourceUserTokenCreate(ctx context.Context, d *schema.ResourceData, meta i
 	c := meta.(*client.Client)
 	name := d.Get("name").(string)
 
-	var expiresAt *time.Time
-	if v, ok := d.GetOk("expire_at"); ok {
-		t, err := time.Parse(time.RFC3339, v.(string))
-		if err != nil {
-			return diag.Errorf("invalid expire_at format: %s", err)
-		}
-		expiresAt = &t
+	expiryKey := "expires_at"
+	if !d.GetRawConfig().GetAttr("expire_at").IsNull() {
+		expiryKey = "expire_at"
+	}
+	expiresAt, err := configuredExpiry(d, expiryKey, time.Now())
+	if err != nil {
+		return diag.FromErr(err)
 	}
 
 	tflog.Debug(ctx, "Creating user token", map[string]interface{}{
-		"name":      name,
-		"expire_at": expiresAt,
+		"name":       name,
+		"expires_at": expiresAt,
 	})
 
 	created, err := c.CreateMyUserToken(ctx, name, expiresAt)
@@ -80,6 +121,9 @@ func resourceUserTokenCreate(ctx context.Context, d *schema.ResourceData, meta i
 	if err := d.Set("value", created.Secret); err != nil {
 		return diag.Errorf("error setting token value: %s", err)
 	}
+	if err := d.Set("imported", false); err != nil {
+		return diag.Errorf("error setting imported: %s", err)
+	}
 
 	return resourceUserTokenRead(ctx, d, meta)
 }
@@ -97,16 +141,33 @@ func resourceUserTokenRead(ctx context.Context, d *schema.ResourceData, meta int
 	}
 
 	token, err := c.GetMyUserToken(ctx, &tid)
+	return setUserTokenState(ctx, d, token, err)
+}
+
+// resourceUserTokenImport accepts the UUID of a token of the provider user. Its secret cannot be read back.
+func resourceUserTokenImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	c := meta.(*client.Client)
+	if c.UserID == "" {
+		return nil, fmt.Errorf("importing a user token requires the provider user_id")
+	}
+	tid, err := uuid.FromString(d.Id())
 	if err != nil {
-		return diag.Errorf("error reading token %s: %s", id, err)
+		return nil, fmt.Errorf("import id must be the token UUID: %w", err)
 	}
-	if err := d.Set("name", token.Name); err != nil {
-		return diag.FromErr(fmt.Errorf("failed to set name: %w", err))
+	d.SetId(tid.String())
+	if err := d.Set("value", ""); err != nil {
+		return nil, fmt.Errorf("failed to set value: %w", err)
 	}
-	if err := d.Set("expire_at", token.ExpiresAt.Format(time.RFC3339)); err != nil {
-		return diag.FromErr(fmt.Errorf("failed to set expire_at: %w", err))
+	if err := d.Set("imported", true); err != nil {
+		return nil, fmt.Errorf("failed to set imported: %w", err)
 	}
-	return nil
+	tflog.Info(ctx, "Imported user token, its value is only available when the token is created", map[string]interface{}{"token_id": d.Id()})
+	return []*schema.ResourceData{d}, nil
+}
+
+// resourceUserTokenUpdate only handles renew_before, which is kept in the state and needs no API call
+func resourceUserTokenUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return resourceUserTokenRead(ctx, d, meta)
 }
 
 func resourceUserTokenDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
@@ -129,3 +190,35 @@ func resourceUserTokenDelete(ctx context.Context, d *schema.ResourceData, meta i
 	d.SetId("")
 	return nil
 }
+
+// setUserTokenState stores a token read with GetMyUserToken, removing the resource when the token is gone.
+// Tokens without expiry come back with a zero expires_at, which is stored as empty.
+func setUserTokenState(ctx context.Context, d *schema.ResourceData, token *iam.IamUserToken, err error) diag.Diagnostics {
+	if err != nil {
+		if errors.Is(err, client.ErrNotFound) {
+			tflog.Warn(ctx, "User token not found, removing from state", map[string]interface{}{"token_id": d.Id()})
+			d.SetId("")
+			return nil
+		}
+		return diag.Errorf("error reading token %s: %s", d.Id(), err)
+	}
+	if err := d.Set("name", token.Name); err != nil {
+		return diag.FromErr(fmt.Errorf("failed to set name: %w", err))
+	}
+	expiresAt := ""
+	if !token.ExpiresAt.IsZero() {
+		expiresAt = token.ExpiresAt.Format(time.RFC3339)
+	}
+	for _, key := range []string{"expires_at", "expire_at"} {
+		if err := d.Set(key, expiresAt); err != nil {
+			return diag.FromErr(fmt.Errorf("failed to set %s: %w", key, err))
+		}
+	}
+	return nil
+}
+
+// expires_in only applies when a token is created, so setting it on an imported token does not replace the
+// token. Changing it on a token created by the resource replaces the token as usual.
+func suppressImportedExpiresIn(k, old, new string, d *schema.ResourceData) bool {
+	return old == "" && d.Get("imported").(bool)
+}